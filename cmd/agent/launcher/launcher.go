@@ -15,9 +15,15 @@ package launcher
 import (
 	"fmt"
 	"github.com/metaform/cfm-fulcrum/internal/client"
+	"github.com/metaform/cfm-fulcrum/internal/deployment"
+	"github.com/metaform/cfm-fulcrum/internal/health"
+	"github.com/metaform/cfm-fulcrum/internal/heartbeat"
 	"github.com/metaform/cfm-fulcrum/internal/job"
 	"github.com/metaform/cfm-fulcrum/internal/management"
+	"github.com/metaform/cfm-fulcrum/internal/metrics"
+	"github.com/metaform/cfm-fulcrum/internal/registration"
 	"github.com/metaform/cfm-fulcrum/internal/sysconfig"
+	"github.com/metaform/cfm-fulcrum/internal/telemetry"
 	"github.com/metaform/connector-fabric-manager/assembly/httpclient"
 	"github.com/metaform/connector-fabric-manager/assembly/routing"
 	"github.com/metaform/connector-fabric-manager/common/config"
@@ -60,9 +66,15 @@ func Launch(shutdown <-chan struct{}) {
 	assembler.Register(&httpclient.HttpClientServiceAssembly{})
 	assembler.Register(&routing.RouterServiceAssembly{})
 
+	assembler.Register(&telemetry.TelemetryServiceAssembly{})
 	assembler.Register(&client.ClientServiceAssembly{})
+	assembler.Register(&registration.RegistrationServiceAssembly{})
 	assembler.Register(&job.JobServiceAssembly{})
+	assembler.Register(&deployment.DeploymentServiceAssembly{})
+	assembler.Register(&heartbeat.HeartbeatServiceAssembly{})
+	assembler.Register(&metrics.MetricsServiceAssembly{})
 	assembler.Register(&management.ManagementServiceAssembly{})
+	assembler.Register(&health.HealthServiceAssembly{})
 
 	runtime.AssembleAndLaunch(assembler, agentName, logMonitor, shutdown)
 }
@@ -13,6 +13,7 @@
 package scenario
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/metaform/cfm-fulcrum/internal/client"
@@ -81,7 +82,8 @@ func CreateTestActivityDefinition(apiClient *client.ApiClient) error {
 		Description: "Performs a test activity",
 	}
 
-	return apiClient.PostToPManager("activity-definition", requestBody)
+	_, err := apiClient.PostToPManager(context.Background(), "activity-definition", requestBody)
+	return err
 }
 
 func CreateTestDeploymentDefinition(apiClient *client.ApiClient) error {
@@ -108,7 +110,8 @@ func CreateTestDeploymentDefinition(apiClient *client.ApiClient) error {
 		},
 	}
 
-	return apiClient.PostToPManager("deployment-definition", requestBody)
+	_, err := apiClient.PostToPManager(context.Background(), "deployment-definition", requestBody)
+	return err
 }
 
 //func CreateTestDeployment() error {
@@ -47,16 +47,21 @@ resource "kubernetes_deployment" "cfm-agent" {
           name              = "cfm-agent"
           image_pull_policy = var.pull_policy
 
-          # port {
-          #   container_port = var.cfm-agent_port
-          #   name           = "http"
-          # }
-          #
+          port {
+            container_port = var.cfm-agent_port
+            name           = "http"
+          }
+
           # port {
           #   container_port = var.metrics_port
           #   name           = "metrics"
           # }
 
+          env {
+            name  = "CFM-AGENT_HTTPPORT"
+            value = tostring(var.cfm-agent_port)
+          }
+
           env {
             name  = "CFM-AGENT_TMANAGER_URL"
             value = var.tmanager_service_url
@@ -83,38 +88,40 @@ resource "kubernetes_deployment" "cfm-agent" {
             requests = var.resources.requests
           }
 
-          # liveness_probe {
-          #   http_get {
-          #     path = "/health"
-          #     port = var.cfm-agent_port
-          #   }
-          #   initial_delay_seconds = 30
-          #   period_seconds        = 10
-          #   timeout_seconds       = 5
-          #   failure_threshold     = 3
-          # }
-          #
-          # readiness_probe {
-          #   http_get {
-          #     path = "/ready"
-          #     port = var.cfm-agent_port
-          #   }
-          #   initial_delay_seconds = 5
-          #   period_seconds        = 5
-          #   timeout_seconds       = 3
-          #   failure_threshold     = 3
-          # }
-          #
-          # startup_probe {
-          #   http_get {
-          #     path = "/health"
-          #     port = var.cfm-agent_port
-          #   }
-          #   initial_delay_seconds = 10
-          #   period_seconds        = 10
-          #   timeout_seconds       = 3
-          #   failure_threshold     = 10
-          # }
+          # /healthz reports whether the job poll loop is making progress
+          liveness_probe {
+            http_get {
+              path = "/healthz"
+              port = var.cfm-agent_port
+            }
+            initial_delay_seconds = 30
+            period_seconds        = 10
+            timeout_seconds       = 5
+            failure_threshold     = 3
+          }
+
+          # /readyz reports whether Fulcrum Core accepts the agent token and the Provision and Tenant Managers are reachable
+          readiness_probe {
+            http_get {
+              path = "/readyz"
+              port = var.cfm-agent_port
+            }
+            initial_delay_seconds = 5
+            period_seconds        = 5
+            timeout_seconds       = 5
+            failure_threshold     = 3
+          }
+
+          startup_probe {
+            http_get {
+              path = "/healthz"
+              port = var.cfm-agent_port
+            }
+            initial_delay_seconds = 10
+            period_seconds        = 10
+            timeout_seconds       = 3
+            failure_threshold     = 10
+          }
         }
       }
     }
@@ -14,26 +14,97 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/metaform/connector-fabric-manager/common/monitor"
 	"io"
 	"net/http"
+	"time"
 )
 
 type ApiClient struct {
 	pmanagerBaseUrl    string
 	fulcrumCoreBaseUrl string
 	cfmAgentBaseUrl    string
-	client             http.Client
+	client             *http.Client
+	pmanagerClient     *http.Client // Client used for Process Manager requests
+
+	pmanagerTransport   http.RoundTripper
+	pmanagerTimeout     time.Duration
+	pmanagerRetryPolicy *RetryPolicy
+	pmanagerLogMonitor  monitor.LogMonitor
+	pmanagerObserver    RequestObserver
+}
+
+// ApiClientOption configures an ApiClient
+type ApiClientOption func(*ApiClient)
+
+// WithPManagerTransport sets the transport used for Process Manager requests, e.g. one configured for TLS
+func WithPManagerTransport(transport http.RoundTripper) ApiClientOption {
+	return func(c *ApiClient) {
+		c.pmanagerTransport = transport
+	}
+}
+
+// WithPManagerRequestTimeout sets the timeout of a single Process Manager request attempt. It defaults to
+// DefaultRequestTimeout.
+func WithPManagerRequestTimeout(timeout time.Duration) ApiClientOption {
+	return func(c *ApiClient) {
+		c.pmanagerTimeout = timeout
+	}
+}
+
+// WithPManagerRetryPolicy enables retries of Process Manager requests. Deployment requests are idempotent since
+// manifests carry deterministic IDs, by which the Process Manager de-duplicates POST /deployment.
+func WithPManagerRetryPolicy(policy RetryPolicy) ApiClientOption {
+	return func(c *ApiClient) {
+		c.pmanagerRetryPolicy = &policy
+	}
+}
+
+// WithPManagerRequestLogging logs every Process Manager request attempt at debug level. Credentials are masked.
+func WithPManagerRequestLogging(logMonitor monitor.LogMonitor) ApiClientOption {
+	return func(c *ApiClient) {
+		c.pmanagerLogMonitor = logMonitor
+	}
 }
 
-func NewApiClient(pmanagerBaseUrl string, fulcrumCoreBaseUrl string, cfmAgentBaseUrl string) *ApiClient {
-	return &ApiClient{
+// WithPManagerRequestObserver reports every Process Manager request attempt to the observer
+func WithPManagerRequestObserver(observer RequestObserver) ApiClientOption {
+	return func(c *ApiClient) {
+		c.pmanagerObserver = observer
+	}
+}
+
+func NewApiClient(pmanagerBaseUrl string, fulcrumCoreBaseUrl string, cfmAgentBaseUrl string, options ...ApiClientOption) *ApiClient {
+	client := &ApiClient{
 		pmanagerBaseUrl:    pmanagerBaseUrl,
 		fulcrumCoreBaseUrl: fulcrumCoreBaseUrl,
 		cfmAgentBaseUrl:    cfmAgentBaseUrl,
-		client:             http.Client{},
+		client:             &http.Client{},
+		pmanagerTimeout:    DefaultRequestTimeout,
+	}
+	for _, option := range options {
+		option(client)
+	}
+
+	transport := http.DefaultTransport
+	if client.pmanagerTransport != nil {
+		transport = client.pmanagerTransport
+	}
+	if client.pmanagerLogMonitor != nil {
+		transport = newLoggingTransport(transport, "PManager", client.pmanagerLogMonitor)
 	}
+	if client.pmanagerObserver != nil {
+		transport = NewObservingTransport(transport, UpstreamPManager, client.pmanagerObserver)
+	}
+	transport = NewTracingTransport(transport, UpstreamPManager)
+	client.pmanagerClient = &http.Client{Transport: transport, Timeout: client.pmanagerTimeout}
+	if client.pmanagerRetryPolicy != nil {
+		client.pmanagerClient = newRetryingClient(*client.pmanagerRetryPolicy, transport, client.pmanagerTimeout)
+	}
+	return client
 }
 
 // PostToFulcrumCore makes a POST request to Fulcrum Core API with authentication
@@ -42,31 +113,47 @@ func (c *ApiClient) PostToFulcrumCore(endpoint string, token string, payload any
 	headers := map[string]string{
 		"Authorization": fmt.Sprintf("Bearer %s", token),
 	}
-	return c.postRequest(url, payload, headers)
+	return c.postRequest(context.Background(), c.client, url, payload, headers)
 }
 
-// PostToPManager makes a POST request to Process Manager API
-func (c *ApiClient) PostToPManager(endpoint string, payload any) error {
+// PostToPManager makes a POST request to Process Manager API and returns the response body. The request is bound to
+// the context, which also carries the trace context propagated to the Process Manager.
+func (c *ApiClient) PostToPManager(ctx context.Context, endpoint string, payload any) ([]byte, error) {
 	url := fmt.Sprintf("%s/%s", c.pmanagerBaseUrl, endpoint)
-	_, err := c.postRequest(url, payload, nil)
-	return err
+	return c.postRequest(ctx, c.pmanagerClient, url, payload, nil)
+}
+
+// GetFromPManager makes a GET request to Process Manager API and returns the response body. The request, including
+// its retries, is bound to the context.
+func (c *ApiClient) GetFromPManager(ctx context.Context, endpoint string) ([]byte, error) {
+	url := fmt.Sprintf("%s/%s", c.pmanagerBaseUrl, endpoint)
+	return c.getRequest(ctx, c.pmanagerClient, url, nil)
+}
+
+// PManagerCheck returns a check that fails unless the Process Manager answers a GET request to the path, e.g. its
+// health endpoint. The request is bound to the context passed to the check.
+func PManagerCheck(apiClient ApiClient, path string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		_, err := apiClient.GetFromPManager(ctx, path)
+		return err
+	}
 }
 
 // PostToCFMAgent makes a POST request to CFM Agent API
 func (c *ApiClient) PostToCFMAgent(endpoint string, payload any) error {
 	url := fmt.Sprintf("%s/%s", c.cfmAgentBaseUrl, endpoint)
-	_, err := c.postRequest(url, payload, nil)
+	_, err := c.postRequest(context.Background(), c.client, url, payload, nil)
 	return err
 }
 
 // postRequest handles POST requests with JSON payload
-func (c *ApiClient) postRequest(url string, payload any, headers map[string]string) ([]byte, error) {
+func (c *ApiClient) postRequest(ctx context.Context, httpClient *http.Client, url string, payload any, headers map[string]string) ([]byte, error) {
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -76,7 +163,7 @@ func (c *ApiClient) postRequest(url string, payload any, headers map[string]stri
 		req.Header.Set(key, value)
 	}
 
-	resp, err := c.client.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make request: %w", err)
 	}
@@ -88,7 +175,37 @@ func (c *ApiClient) postRequest(url string, payload any, headers map[string]stri
 	}
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent {
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, newHTTPErrorWithBody(resp, body)
+	}
+
+	return body, nil
+}
+
+// getRequest handles GET requests returning a JSON payload
+func (c *ApiClient) getRequest(ctx context.Context, httpClient *http.Client, url string, headers map[string]string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newHTTPErrorWithBody(resp, body)
 	}
 
 	return body, nil
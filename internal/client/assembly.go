@@ -13,21 +13,86 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"github.com/metaform/cfm-fulcrum/internal/sysconfig"
+	"github.com/metaform/connector-fabric-manager/common/monitor"
 	"github.com/metaform/connector-fabric-manager/common/runtime"
 	"github.com/metaform/connector-fabric-manager/common/system"
+	"net/http"
+	"time"
 )
 
 const (
 	FulcrumClientKey system.ServiceType = "client:FulcrumClient"
 	ApiClientKey     system.ServiceType = "client:ApiClient"
-	fulcrumUri                          = "fulcrum.uri"
-	fulcrumToken                        = "fulcrum.token"
+	// TManagerHttpClientKey resolves an *http.Client configured for connections to the Tenant Manager
+	TManagerHttpClientKey system.ServiceType = "client:TManagerHttpClient"
+	// TokenTrackerKey resolves the *TokenTracker if the agent authenticates with a static token
+	TokenTrackerKey system.ServiceType = "client:TokenTracker"
+	// RequestObserverKey resolves the RequestObserver notified of requests to upstream services
+	RequestObserverKey system.ServiceType = "client:RequestObserver"
+)
+
+const (
+	fulcrumUri                 = "fulcrum.uri"
+	fulcrumToken               = "fulcrum.token"
+	fulcrumTimeout             = "fulcrum.timeout"
+	fulcrumLogRequests         = "fulcrum.log_requests"
+	pmanagerLogRequests        = "pmanager.log_requests"
+	pmanagerTimeout            = "pmanager.timeout"
+	fulcrumMetricsConcurrency  = "fulcrum.metrics_concurrency"
+	fulcrumMetricsBatchSize    = "fulcrum.metrics_batch_size"
+	fulcrumAuthType            = "fulcrum.auth.type"
+	fulcrumAuthTokenFile       = "fulcrum.auth.token_file"
+	fulcrumSecretFile          = "fulcrum.auth.secret_file"
+	fulcrumTokenExpiryWarning  = "fulcrum.auth.expiry_warning"
+	fulcrumOAuth2TokenUrl      = "fulcrum.auth.oauth2.token_url"
+	fulcrumOAuth2ClientId      = "fulcrum.auth.oauth2.client_id"
+	fulcrumOAuth2ClientSecret  = "fulcrum.auth.oauth2.client_secret"
+	fulcrumOAuth2Scopes        = "fulcrum.auth.oauth2.scopes"
+	fulcrumOAuth2RefreshBefore = "fulcrum.auth.oauth2.refresh_before"
+)
+
+const (
+	defaultTokenExpiryWarning = 24 * time.Hour
+	tokenCheckInterval        = time.Hour
+)
+
+// Supported values of the fulcrum.auth.type setting
+const (
+	AuthTypeStatic = "static"
+	AuthTypeFile   = "file"
+	AuthTypeOAuth2 = "oauth2"
+)
+
+// Retry and TLS settings are configured per upstream service under these prefixes
+const (
+	fulcrumRetry  = "fulcrum.retry"
+	pmanagerRetry = "pmanager.retry"
+	fulcrumTLS    = "fulcrum.tls"
+	pmanagerTLS   = "pmanager.tls"
+	tmanagerTLS   = "tmanager.tls"
+
+	retryMaxRetries   = "max_retries"
+	retryWaitMin      = "wait_min"
+	retryWaitMax      = "wait_max"
+	retryMaxElapsed   = "max_elapsed"
+	tlsCAFile         = "ca_file"
+	tlsCertFile       = "cert_file"
+	tlsKeyFile        = "key_file"
+	tlsMinVersion     = "min_version"
+	tlsServerName     = "server_name"
+	tlsReloadInterval = "reload_interval"
 )
 
 type ClientServiceAssembly struct {
 	system.DefaultServiceAssembly
+	tokenTracker  *TokenTracker
+	expiryWarning time.Duration
+	monitor       monitor.LogMonitor
+	cancel        context.CancelFunc
+	done          chan struct{}
 }
 
 func (a *ClientServiceAssembly) Name() string {
@@ -35,7 +100,11 @@ func (a *ClientServiceAssembly) Name() string {
 }
 
 func (d *ClientServiceAssembly) Provides() []system.ServiceType {
-	return []system.ServiceType{FulcrumClientKey, ApiClientKey}
+	return []system.ServiceType{FulcrumClientKey, ApiClientKey, TManagerHttpClientKey, TokenTrackerKey}
+}
+
+func (d *ClientServiceAssembly) Requires() []system.ServiceType {
+	return []system.ServiceType{RequestObserverKey}
 }
 
 func (a *ClientServiceAssembly) Init(ctx *system.InitContext) error {
@@ -44,20 +113,227 @@ func (a *ClientServiceAssembly) Init(ctx *system.InitContext) error {
 	tmanagerUrl := ctx.Config.GetString(sysconfig.TManagerUrlKey)
 	pmanagerUrl := ctx.Config.GetString(sysconfig.PManagerUrlKey)
 
-	err := runtime.CheckRequiredParams(
+	authType := ctx.GetConfigStrOrDefault(fulcrumAuthType, AuthTypeStatic)
+	secretFile := ctx.Config.GetString(fulcrumSecretFile)
+	if authType == AuthTypeStatic && secretFile != "" {
+		// a token persisted at runtime replaces the configured one
+		persisted, err := ReadSecretFile(secretFile)
+		if err != nil {
+			return err
+		}
+		if persisted != "" {
+			token = persisted
+		}
+	}
+
+	required := []any{
 		fmt.Sprintf("%s.%s", ctx.Config.GetEnvPrefix(), fulcrumUri), uri,
-		fmt.Sprintf("%s.%s", ctx.Config.GetEnvPrefix(), fulcrumToken), token,
 		fmt.Sprintf("%s.%s", ctx.Config.GetEnvPrefix(), sysconfig.TManagerUrlKey), tmanagerUrl,
-		fmt.Sprintf("%s.%s", ctx.Config.GetEnvPrefix(), sysconfig.PManagerUrlKey), pmanagerUrl)
+		fmt.Sprintf("%s.%s", ctx.Config.GetEnvPrefix(), sysconfig.PManagerUrlKey), pmanagerUrl,
+	}
+	if authType == AuthTypeStatic {
+		required = append(required, fmt.Sprintf("%s.%s", ctx.Config.GetEnvPrefix(), fulcrumToken), token)
+	}
+	err := runtime.CheckRequiredParams(required...)
 	if err != nil {
 		panic(fmt.Errorf("error launching %s: %w", a.Name(), err))
 	}
 
-	fulcrumClient := NewHTTPFulcrumClient(uri, token)
+	timeout := DefaultRequestTimeout
+	if ctx.Config.IsSet(fulcrumTimeout) {
+		timeout = ctx.Config.GetDuration(fulcrumTimeout)
+	}
+	pmanagerRequestTimeout := DefaultRequestTimeout
+	if ctx.Config.IsSet(pmanagerTimeout) {
+		pmanagerRequestTimeout = ctx.Config.GetDuration(pmanagerTimeout)
+	}
+
+	fulcrumPolicy, err := loadRetryPolicy(ctx, fulcrumRetry)
+	if err != nil {
+		return err
+	}
+	pmanagerPolicy, err := loadRetryPolicy(ctx, pmanagerRetry)
+	if err != nil {
+		return err
+	}
+
+	fulcrumTransport, err := loadTransport(ctx, fulcrumTLS)
+	if err != nil {
+		return err
+	}
+	pmanagerTransport, err := loadTransport(ctx, pmanagerTLS)
+	if err != nil {
+		return err
+	}
+	tmanagerTransport, err := loadTransport(ctx, tmanagerTLS)
+	if err != nil {
+		return err
+	}
+
+	observer := ctx.Registry.Resolve(RequestObserverKey).(RequestObserver)
+
+	auth, err := loadAuthenticator(ctx, authType, token, fulcrumTransport)
+	if err != nil {
+		return err
+	}
+
+	fulcrumOptions := []FulcrumClientOption{
+		WithTransport(fulcrumTransport),
+		WithRetryPolicy(fulcrumPolicy),
+		WithAuthenticator(auth),
+		WithMetricsConcurrency(ctx.GetConfigIntOrDefault(fulcrumMetricsConcurrency, DefaultMetricsConcurrency)),
+		WithMetricsBatchSize(ctx.GetConfigIntOrDefault(fulcrumMetricsBatchSize, DefaultMetricsBatchSize)),
+		WithRequestObserver(observer),
+	}
+	if ctx.Config.GetBool(fulcrumLogRequests) {
+		fulcrumOptions = append(fulcrumOptions, WithRequestLogging(ctx.LogMonitor))
+	}
+	fulcrumClient := NewHTTPFulcrumClient(uri, token, timeout, fulcrumOptions...)
 	ctx.Registry.Register(FulcrumClientKey, fulcrumClient)
 
-	apiClient := NewApiClient(pmanagerUrl, fulcrumUri, "not-used")
+	if authType == AuthTypeStatic {
+		a.tokenTracker = NewTokenTracker(token, secretFile, time.Now())
+		ctx.Registry.Register(TokenTrackerKey, a.tokenTracker)
+	}
+	a.expiryWarning = defaultTokenExpiryWarning
+	if ctx.Config.IsSet(fulcrumTokenExpiryWarning) {
+		a.expiryWarning = ctx.Config.GetDuration(fulcrumTokenExpiryWarning)
+	}
+	a.monitor = ctx.LogMonitor
+
+	apiOptions := []ApiClientOption{
+		WithPManagerTransport(pmanagerTransport),
+		WithPManagerRetryPolicy(pmanagerPolicy),
+		WithPManagerRequestTimeout(pmanagerRequestTimeout),
+		WithPManagerRequestObserver(observer),
+	}
+	if ctx.Config.GetBool(pmanagerLogRequests) {
+		apiOptions = append(apiOptions, WithPManagerRequestLogging(ctx.LogMonitor))
+	}
+	apiClient := NewApiClient(pmanagerUrl, fulcrumUri, "not-used", apiOptions...)
 	ctx.Registry.Register(ApiClientKey, *apiClient)
 
+	tmanagerClient := &http.Client{
+		Transport: NewTracingTransport(NewObservingTransport(tmanagerTransport, UpstreamTManager, observer), UpstreamTManager),
+		Timeout:   DefaultRequestTimeout,
+	}
+	ctx.Registry.Register(TManagerHttpClientKey, tmanagerClient)
+
 	return nil
 }
+
+// Start periodically warns if the token is about to expire
+func (a *ClientServiceAssembly) Start(_ *system.StartContext) error {
+	if a.tokenTracker == nil || a.expiryWarning <= 0 {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
+	a.done = make(chan struct{})
+
+	go func() {
+		defer close(a.done)
+		ticker := time.NewTicker(tokenCheckInterval)
+		defer ticker.Stop()
+		for {
+			a.checkTokenExpiry()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+func (a *ClientServiceAssembly) Finalize() error {
+	if a.cancel != nil {
+		a.cancel()
+		<-a.done
+	}
+	return nil
+}
+
+func (a *ClientServiceAssembly) checkTokenExpiry() {
+	info := a.tokenTracker.Info()
+	now := time.Now()
+	if info.ExpiresWithin(a.expiryWarning, now) {
+		if info.ExpiresAt.Before(now) {
+			a.monitor.Warnf("Fulcrum token expired at %s", info.ExpiresAt.Format(time.RFC3339))
+		} else {
+			a.monitor.Warnf("Fulcrum token expires at %s, update it through the management API", info.ExpiresAt.Format(time.RFC3339))
+		}
+	}
+}
+
+// loadAuthenticator creates the authenticator selected by the fulcrum.auth.type setting
+func loadAuthenticator(ctx *system.InitContext, authType string, token string, transport http.RoundTripper) (Authenticator, error) {
+	switch authType {
+	case AuthTypeStatic:
+		return NewStaticTokenAuthenticator(token), nil
+	case AuthTypeFile:
+		auth, err := NewFileTokenAuthenticator(ctx.Config.GetString(fulcrumAuthTokenFile))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s configuration: %w", fulcrumAuthTokenFile, err)
+		}
+		return auth, nil
+	case AuthTypeOAuth2:
+		config := OAuth2Config{
+			TokenURL:      ctx.Config.GetString(fulcrumOAuth2TokenUrl),
+			ClientID:      ctx.Config.GetString(fulcrumOAuth2ClientId),
+			ClientSecret:  ctx.Config.GetString(fulcrumOAuth2ClientSecret),
+			Scopes:        ctx.Config.GetStringSlice(fulcrumOAuth2Scopes),
+			RefreshBefore: ctx.Config.GetDuration(fulcrumOAuth2RefreshBefore),
+		}
+		auth, err := NewOAuth2Authenticator(config, &http.Client{Transport: transport, Timeout: DefaultRequestTimeout})
+		if err != nil {
+			return nil, fmt.Errorf("invalid fulcrum.auth.oauth2 configuration: %w", err)
+		}
+		return auth, nil
+	}
+	return nil, fmt.Errorf("invalid %s: unsupported authenticator %q", fulcrumAuthType, authType)
+}
+
+// loadTransport returns a transport using the TLS settings under the given prefix, or the default transport if none
+// are configured
+func loadTransport(ctx *system.InitContext, prefix string) (http.RoundTripper, error) {
+	config := TLSConfig{
+		CAFile:     ctx.Config.GetString(prefix + "." + tlsCAFile),
+		CertFile:   ctx.Config.GetString(prefix + "." + tlsCertFile),
+		KeyFile:    ctx.Config.GetString(prefix + "." + tlsKeyFile),
+		MinVersion: ctx.Config.GetString(prefix + "." + tlsMinVersion),
+		ServerName: ctx.Config.GetString(prefix + "." + tlsServerName),
+	}
+	if !config.IsSet() {
+		return http.DefaultTransport, nil
+	}
+	reloadInterval := DefaultTLSReloadInterval
+	if key := prefix + "." + tlsReloadInterval; ctx.Config.IsSet(key) {
+		reloadInterval = ctx.Config.GetDuration(key)
+	}
+	transport, err := NewTLSTransport(config, reloadInterval, ctx.LogMonitor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s configuration: %w", prefix, err)
+	}
+	return transport, nil
+}
+
+// loadRetryPolicy reads the retry settings under the given prefix, falling back to the default policy
+func loadRetryPolicy(ctx *system.InitContext, prefix string) (RetryPolicy, error) {
+	policy := DefaultRetryPolicy()
+	policy.MaxRetries = ctx.GetConfigIntOrDefault(prefix+"."+retryMaxRetries, policy.MaxRetries)
+	if key := prefix + "." + retryWaitMin; ctx.Config.IsSet(key) {
+		policy.WaitMin = ctx.Config.GetDuration(key)
+	}
+	if key := prefix + "." + retryWaitMax; ctx.Config.IsSet(key) {
+		policy.WaitMax = ctx.Config.GetDuration(key)
+	}
+	if key := prefix + "." + retryMaxElapsed; ctx.Config.IsSet(key) {
+		policy.MaxElapsed = ctx.Config.GetDuration(key)
+	}
+	if err := policy.Validate(); err != nil {
+		return policy, fmt.Errorf("invalid %s configuration: %w", prefix, err)
+	}
+	return policy, nil
+}
@@ -0,0 +1,225 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Authenticator supplies the bearer token sent with Fulcrum Core requests
+type Authenticator interface {
+	// Token returns the token to use for the next request
+	Token(ctx context.Context) (string, error)
+
+	// Invalidate is called when a request with the token was rejected as unauthorized. Authenticators that can obtain
+	// a new token should do so on the next call to Token; the request is then retried once.
+	Invalidate(token string)
+}
+
+// TokenUpdater is implemented by authenticators whose token can be replaced at runtime
+type TokenUpdater interface {
+	UpdateToken(token string) error
+}
+
+// StaticTokenAuthenticator authenticates with a fixed token that can be replaced with UpdateToken
+type StaticTokenAuthenticator struct {
+	token atomic.Pointer[string]
+}
+
+func NewStaticTokenAuthenticator(token string) *StaticTokenAuthenticator {
+	a := &StaticTokenAuthenticator{}
+	a.token.Store(&token)
+	return a
+}
+
+func (a *StaticTokenAuthenticator) Token(context.Context) (string, error) {
+	return *a.token.Load(), nil
+}
+
+func (a *StaticTokenAuthenticator) Invalidate(string) {
+}
+
+func (a *StaticTokenAuthenticator) UpdateToken(token string) error {
+	a.token.Store(&token)
+	return nil
+}
+
+// FileTokenAuthenticator reads the token from a file, e.g. a mounted secret. The file is read again when it changes or
+// after the token was rejected.
+type FileTokenAuthenticator struct {
+	path string
+
+	mu      sync.Mutex
+	token   string
+	modTime time.Time
+}
+
+func NewFileTokenAuthenticator(path string) (*FileTokenAuthenticator, error) {
+	a := &FileTokenAuthenticator{path: path}
+	if _, err := a.Token(context.Background()); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *FileTokenAuthenticator) Token(context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	info, err := os.Stat(a.path)
+	if err != nil {
+		if a.token != "" {
+			// the file may be in the middle of being replaced
+			return a.token, nil
+		}
+		return "", fmt.Errorf("failed to read token file: %w", err)
+	}
+	if a.token != "" && info.ModTime().Equal(a.modTime) {
+		return a.token, nil
+	}
+
+	data, err := os.ReadFile(a.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", a.path)
+	}
+	a.token = token
+	a.modTime = info.ModTime()
+	return token, nil
+}
+
+func (a *FileTokenAuthenticator) Invalidate(token string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.token == token {
+		a.modTime = time.Time{}
+	}
+}
+
+// DefaultRefreshBefore is how long before expiry an OAuth2 access token is refreshed
+const DefaultRefreshBefore = 30 * time.Second
+
+// OAuth2Config configures the OAuth2 client credentials grant
+type OAuth2Config struct {
+	TokenURL      string
+	ClientID      string
+	ClientSecret  string
+	Scopes        []string
+	RefreshBefore time.Duration // Refresh the token this long before it expires
+}
+
+// OAuth2Authenticator obtains access tokens with the OAuth2 client credentials grant and refreshes them before they
+// expire
+type OAuth2Authenticator struct {
+	config     OAuth2Config
+	httpClient *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func NewOAuth2Authenticator(config OAuth2Config, httpClient *http.Client) (*OAuth2Authenticator, error) {
+	if config.TokenURL == "" || config.ClientID == "" {
+		return nil, errors.New("token URL and client ID are required")
+	}
+	if config.RefreshBefore <= 0 {
+		config.RefreshBefore = DefaultRefreshBefore
+	}
+	return &OAuth2Authenticator{config: config, httpClient: httpClient}, nil
+}
+
+func (a *OAuth2Authenticator) Token(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token != "" && (a.expires.IsZero() || time.Until(a.expires) > a.config.RefreshBefore) {
+		return a.token, nil
+	}
+	token, expiresIn, err := a.requestToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	a.token = token
+	a.expires = time.Time{}
+	if expiresIn > 0 {
+		a.expires = time.Now().Add(expiresIn)
+	}
+	return token, nil
+}
+
+func (a *OAuth2Authenticator) Invalidate(token string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.token == token {
+		a.token = ""
+	}
+}
+
+func (a *OAuth2Authenticator) requestToken(ctx context.Context) (string, time.Duration, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(a.config.Scopes) > 0 {
+		form.Set("scope", strings.Join(a.config.Scopes, " "))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(a.config.ClientID), url.QueryEscape(a.config.ClientSecret))
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to request access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read access token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("failed to request access token: %w", newHTTPErrorWithBody(resp, body))
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", 0, fmt.Errorf("failed to decode access token response: %w", err)
+	}
+	if result.AccessToken == "" {
+		return "", 0, errors.New("access token response contains no token")
+	}
+	if result.TokenType != "" && !strings.EqualFold(result.TokenType, "bearer") {
+		return "", 0, fmt.Errorf("unsupported token type %q", result.TokenType)
+	}
+	return result.AccessToken, time.Duration(result.ExpiresIn) * time.Second, nil
+}
@@ -0,0 +1,140 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOAuth2Authenticator(t *testing.T) {
+	var issued atomic.Int32
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientID, secret, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "agent", clientID)
+		assert.Equal(t, "secret", secret)
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "jobs metrics", r.PostForm.Get("scope"))
+
+		n := issued.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": fmt.Sprintf("token-%d", n),
+			"token_type":   "Bearer",
+			"expires_in":   60,
+		})
+	}))
+	defer tokenServer.Close()
+
+	config := OAuth2Config{
+		TokenURL:     tokenServer.URL,
+		ClientID:     "agent",
+		ClientSecret: "secret",
+		Scopes:       []string{"jobs", "metrics"},
+	}
+
+	t.Run("caches until refresh", func(t *testing.T) {
+		auth, err := NewOAuth2Authenticator(config, http.DefaultClient)
+		require.NoError(t, err)
+
+		first, err := auth.Token(context.Background())
+		require.NoError(t, err)
+		second, err := auth.Token(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, first, second)
+
+		// a token within the refresh window is replaced
+		auth.expires = time.Now().Add(10 * time.Second)
+		third, err := auth.Token(context.Background())
+		require.NoError(t, err)
+		assert.NotEqual(t, first, third)
+	})
+
+	t.Run("retries once on 401", func(t *testing.T) {
+		auth, err := NewOAuth2Authenticator(config, http.DefaultClient)
+		require.NoError(t, err)
+		rejected, err := auth.Token(context.Background())
+		require.NoError(t, err)
+
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			if r.Header.Get("Authorization") == "Bearer "+rejected {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "agent-1"})
+		}))
+		defer server.Close()
+
+		client := NewHTTPFulcrumClient(server.URL, "", time.Second, WithAuthenticator(auth))
+		info, err := client.GetAgentInfo(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "agent-1", info["id"])
+		assert.Equal(t, int32(2), calls.Load())
+
+		assert.Error(t, client.UpdateToken("token"), "OAuth2 tokens cannot be replaced")
+	})
+}
+
+func TestFileTokenAuthenticator(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(file, []byte("first\n"), 0600))
+
+	auth, err := NewFileTokenAuthenticator(file)
+	require.NoError(t, err)
+	token, err := auth.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "first", token)
+
+	require.NoError(t, os.WriteFile(file, []byte("second"), 0600))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(file, later, later))
+
+	token, err = auth.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "second", token)
+
+	_, err = NewFileTokenAuthenticator(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
+
+func TestHTTPFulcrumClient_UpdateToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer new" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "agent-1"})
+	}))
+	defer server.Close()
+
+	client := NewHTTPFulcrumClient(server.URL, "old", time.Second)
+	_, err := client.GetAgentInfo(context.Background())
+	assert.True(t, HasStatus(err, http.StatusUnauthorized))
+
+	require.NoError(t, client.UpdateToken("new"))
+	_, err = client.GetAgentInfo(context.Background())
+	assert.NoError(t, err)
+}
@@ -16,11 +16,16 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/metaform/connector-fabric-manager/common/monitor"
+	"io"
 	"net/http"
 	"net/url"
 	"path"
+	"sync"
 	"sync/atomic"
 	"time"
 )
@@ -37,6 +42,16 @@ const (
 	JobActionServiceDelete     JobAction = "ServiceDelete"
 )
 
+// JobActions contains all job actions issued by Fulcrum Core
+var JobActions = []JobAction{
+	JobActionServiceCreate,
+	JobActionServiceStart,
+	JobActionServiceStop,
+	JobActionServiceHotUpdate,
+	JobActionServiceColdUpdate,
+	JobActionServiceDelete,
+}
+
 // JobStatus represents the status of a job
 type JobStatus string
 
@@ -49,22 +64,18 @@ const (
 
 // Job represents a job from the Fulcrum Core job queue
 type Job struct {
-	ID       string    `json:"id"`
-	Action   JobAction `json:"action"`
-	Status   JobStatus `json:"status"`
-	Priority int       `json:"priority"`
-	Service  struct {
-		ID                string  `json:"id"`
-		Name              string  `json:"name"`
-		ExternalID        *string `json:"externalId"`
-		CurrentProperties *struct {
-			CPU    int `json:"cpu"`
-			Memory int `json:"memory"`
-		} `json:"currentProperties"`
-		TargetProperties *struct {
-			CPU    int `json:"cpu"`
-			Memory int `json:"memory"`
-		} `json:"targetProperties"`
+	ID        string    `json:"id"`
+	Action    JobAction `json:"action"`
+	Status    JobStatus `json:"status"`
+	Priority  int       `json:"priority"`
+	CreatedAt time.Time `json:"createdAt"`
+	Service   struct {
+		ID                string         `json:"id"`
+		Name              string         `json:"name"`
+		ServiceTypeID     string         `json:"serviceTypeId"`
+		ExternalID        *string        `json:"externalId"`
+		CurrentProperties map[string]any `json:"currentProperties"`
+		TargetProperties  map[string]any `json:"targetProperties"`
 	} `json:"service"`
 }
 
@@ -76,43 +87,148 @@ type MetricEntry struct {
 	TypeName   string  `json:"typeName"`
 }
 
-// FulcrumClient defines the interface for communication with the Fulcrum Core API
+// FulcrumClient defines the interface for communication with the Fulcrum Core API.
+//
+// Calls are bound to the passed context: cancelling the context aborts an in-flight request. Each request attempt is
+// additionally bounded by the client's request timeout.
 type FulcrumClient interface {
-	UpdateAgentStatus(status string) error
-	GetAgentInfo() (map[string]any, error)
-	GetPendingJobs() ([]*Job, error)
-	ClaimJob(jobID string) error
-	CompleteJob(jobID string, resources any) error
-	FailJob(jobID string, errorMessage string) error
-	ReportMetric(metrics *MetricEntry) error
-
+	UpdateAgentStatus(ctx context.Context, status string) error
+	GetAgentInfo(ctx context.Context) (map[string]any, error)
+	GetPendingJobs(ctx context.Context) ([]*Job, error)
+	ClaimJob(ctx context.Context, jobID string) error
+	CompleteJob(ctx context.Context, jobID string, resources any) error
+	FailJob(ctx context.Context, jobID string, errorMessage string) error
+	ReportMetric(ctx context.Context, metrics *MetricEntry) error
+	ReportMetrics(ctx context.Context, batch []*MetricEntry) error
+
+	ValidateToken(ctx context.Context, token string) (map[string]any, error)
 	UpdateToken(token string) error
 }
 
+// DefaultRequestTimeout is the timeout applied to a single request attempt
+const DefaultRequestTimeout = 30 * time.Second
+
+// DefaultMetricsConcurrency is the number of metric entries submitted in parallel if Fulcrum Core does not accept
+// batches
+const DefaultMetricsConcurrency = 4
+
+// DefaultMetricsBatchSize is the maximum number of metric entries submitted in a single request
+const DefaultMetricsBatchSize = 100
+
 type HTTPFulcrumClient struct {
-	baseURL    string
-	httpClient *http.Client
-	token      atomic.Pointer[string] // Agent authentication token
+	baseURL        string
+	httpClient     *http.Client // Single-shot client
+	retryClient    *http.Client // Client used for idempotent requests
+	requestTimeout time.Duration
+	auth           Authenticator // Supplies the agent authentication token
+
+	transport          http.RoundTripper
+	retryPolicy        *RetryPolicy
+	logMonitor         monitor.LogMonitor
+	observer           RequestObserver
+	metricsConcurrency int
+	metricsBatchSize   int
+
+	metricsBatchUnsupported atomic.Bool // set once Fulcrum Core rejected the batch endpoint
+}
+
+// FulcrumClientOption configures an HTTPFulcrumClient
+type FulcrumClientOption func(*HTTPFulcrumClient)
+
+// WithTransport sets the transport used for requests, e.g. one configured for TLS
+func WithTransport(transport http.RoundTripper) FulcrumClientOption {
+	return func(c *HTTPFulcrumClient) {
+		c.transport = transport
+	}
+}
+
+// WithAuthenticator sets how the client obtains its token. The token passed to the constructor is then ignored.
+func WithAuthenticator(auth Authenticator) FulcrumClientOption {
+	return func(c *HTTPFulcrumClient) {
+		c.auth = auth
+	}
 }
 
-func NewHTTPFulcrumClient(baseURL string, token string) FulcrumClient {
+// WithRetryPolicy enables retries of idempotent requests. Without it, every request is attempted once.
+func WithRetryPolicy(policy RetryPolicy) FulcrumClientOption {
+	return func(c *HTTPFulcrumClient) {
+		c.retryPolicy = &policy
+	}
+}
+
+// WithRequestLogging logs every request attempt at debug level. Credentials are masked.
+func WithRequestLogging(logMonitor monitor.LogMonitor) FulcrumClientOption {
+	return func(c *HTTPFulcrumClient) {
+		c.logMonitor = logMonitor
+	}
+}
+
+// WithRequestObserver reports every request attempt to the observer
+func WithRequestObserver(observer RequestObserver) FulcrumClientOption {
+	return func(c *HTTPFulcrumClient) {
+		c.observer = observer
+	}
+}
+
+// WithMetricsConcurrency limits the number of metric entries submitted in parallel if Fulcrum Core does not accept
+// batches
+func WithMetricsConcurrency(concurrency int) FulcrumClientOption {
+	return func(c *HTTPFulcrumClient) {
+		c.metricsConcurrency = concurrency
+	}
+}
+
+// WithMetricsBatchSize limits the number of metric entries submitted in a single request
+func WithMetricsBatchSize(size int) FulcrumClientOption {
+	return func(c *HTTPFulcrumClient) {
+		c.metricsBatchSize = size
+	}
+}
+
+func NewHTTPFulcrumClient(baseURL string, token string, requestTimeout time.Duration, options ...FulcrumClientOption) FulcrumClient {
 	client := &HTTPFulcrumClient{
-		baseURL: baseURL,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		baseURL:            baseURL,
+		requestTimeout:     requestTimeout,
+		metricsConcurrency: DefaultMetricsConcurrency,
+		metricsBatchSize:   DefaultMetricsBatchSize,
+	}
+	for _, option := range options {
+		option(client)
+	}
+
+	transport := http.DefaultTransport
+	if client.transport != nil {
+		transport = client.transport
+	}
+	if client.logMonitor != nil {
+		transport = newLoggingTransport(transport, "Fulcrum", client.logMonitor)
+	}
+	if client.observer != nil {
+		transport = NewObservingTransport(transport, UpstreamFulcrum, client.observer)
+	}
+	transport = NewTracingTransport(transport, UpstreamFulcrum)
+	client.httpClient = &http.Client{Transport: transport, Timeout: requestTimeout}
+	client.retryClient = client.httpClient
+	if client.retryPolicy != nil {
+		client.retryClient = newRetryingClient(*client.retryPolicy, transport, requestTimeout)
+	}
+	if client.auth == nil {
+		client.auth = NewStaticTokenAuthenticator(token)
 	}
-	client.token.Store(&token)
 	return client
 }
 
+// UpdateToken replaces the token if the client authenticates with a token that can be updated
 func (c *HTTPFulcrumClient) UpdateToken(token string) error {
-	c.token.Store(&token)
-	return nil
+	updater, ok := c.auth.(TokenUpdater)
+	if !ok {
+		return errors.New("the configured authenticator does not accept token updates")
+	}
+	return updater.UpdateToken(token)
 }
 
 // UpdateAgentStatus updates the agent's status in Fulcrum Core
-func (c *HTTPFulcrumClient) UpdateAgentStatus(status string) error {
+func (c *HTTPFulcrumClient) UpdateAgentStatus(ctx context.Context, status string) error {
 	reqBody, err := json.Marshal(map[string]any{
 		"status": status,
 	})
@@ -120,29 +236,47 @@ func (c *HTTPFulcrumClient) UpdateAgentStatus(status string) error {
 		return fmt.Errorf("failed to marshal status update request: %w", err)
 	}
 
-	resp, err := c.put("/api/v1/agents/me/status", reqBody)
+	resp, err := c.put(ctx, "/api/v1/agents/me/status", reqBody)
 	if err != nil {
 		return fmt.Errorf("failed to update agent status: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to update agent status, status: %d", resp.StatusCode)
+		return fmt.Errorf("failed to update agent status: %w", newHTTPError(resp))
 	}
 
 	return nil
 }
 
 // GetAgentInfo retrieves the agent's information from Fulcrum Core
-func (c *HTTPFulcrumClient) GetAgentInfo() (map[string]any, error) {
-	resp, err := c.get("/api/v1/agents/me")
+func (c *HTTPFulcrumClient) GetAgentInfo(ctx context.Context) (map[string]any, error) {
+	resp, err := c.get(ctx, "/api/v1/agents/me")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get agent info: %w", err)
 	}
+	return decodeAgentInfo(resp)
+}
+
+// ValidateToken checks that Fulcrum Core accepts the token by retrieving the agent's information with it. The token
+// used for other requests is not changed.
+func (c *HTTPFulcrumClient) ValidateToken(ctx context.Context, token string) (map[string]any, error) {
+	u, err := c.endpointURL("/api/v1/agents/me")
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.send(ctx, c.retryClient, http.MethodGet, u, nil, token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent info: %w", err)
+	}
+	return decodeAgentInfo(resp)
+}
+
+func decodeAgentInfo(resp *http.Response) (map[string]any, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get agent info, status: %d", resp.StatusCode)
+		return nil, fmt.Errorf("failed to get agent info: %w", newHTTPError(resp))
 	}
 
 	var result map[string]any
@@ -154,15 +288,15 @@ func (c *HTTPFulcrumClient) GetAgentInfo() (map[string]any, error) {
 }
 
 // GetPendingJobs retrieves pending jobs for this agent
-func (c *HTTPFulcrumClient) GetPendingJobs() ([]*Job, error) {
-	resp, err := c.get("/api/v1/jobs/pending")
+func (c *HTTPFulcrumClient) GetPendingJobs(ctx context.Context) ([]*Job, error) {
+	resp, err := c.get(ctx, "/api/v1/jobs/pending")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get pending jobs: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get pending jobs, status: %d", resp.StatusCode)
+		return nil, fmt.Errorf("failed to get pending jobs: %w", newHTTPError(resp))
 	}
 
 	var jobs []*Job
@@ -174,43 +308,92 @@ func (c *HTTPFulcrumClient) GetPendingJobs() ([]*Job, error) {
 	return jobs, nil
 }
 
-// ClaimJob claims a job for processing
-func (c *HTTPFulcrumClient) ClaimJob(jobID string) error {
-	resp, err := c.post(fmt.Sprintf("/api/v1/jobs/%s/claim", jobID), nil)
+// ClaimJob claims a job for processing. A 409 response to the first attempt means the job was claimed by another
+// agent.
+//
+// A retried claim conflicts with its own earlier attempt if that attempt succeeded but its response was lost. A 409
+// response to a retried claim is therefore resolved by reading the job: it is claimed by this agent if it is
+// processing and assigned to the agent.
+func (c *HTTPFulcrumClient) ClaimJob(ctx context.Context, jobID string) error {
+	claimCtx, attempts := countAttempts(ctx)
+	resp, err := c.post(claimCtx, fmt.Sprintf("/api/v1/jobs/%s/claim", jobID), nil)
 	if err != nil {
 		return fmt.Errorf("failed to claim job: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusConflict && attempts.Load() > 1 {
+		claimed, err := c.claimedByAgent(ctx, jobID)
+		if err != nil {
+			return fmt.Errorf("failed to resolve conflicting retried claim of job %s: %w", jobID, err)
+		}
+		if claimed {
+			return nil
+		}
+	}
+
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("failed to claim job, status: %d", resp.StatusCode)
+		return fmt.Errorf("failed to claim job: %w", newHTTPError(resp))
 	}
 
 	return nil
 }
 
+// claimedByAgent reports whether the job is being processed by this agent. It relies on the Fulcrum Core job resource
+// returned by GET /api/v1/jobs/{id} carrying the job status and the ID of the agent the job is assigned to (agentId),
+// and on GET /api/v1/agents/me returning the agent's ID.
+func (c *HTTPFulcrumClient) claimedByAgent(ctx context.Context, jobID string) (bool, error) {
+	info, err := c.GetAgentInfo(ctx)
+	if err != nil {
+		return false, err
+	}
+	agentID, _ := info["id"].(string)
+	if agentID == "" {
+		return false, errors.New("agent info does not contain an id")
+	}
+
+	resp, err := c.get(ctx, fmt.Sprintf("/api/v1/jobs/%s", jobID))
+	if err != nil {
+		return false, fmt.Errorf("failed to get job: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("failed to get job: %w", newHTTPError(resp))
+	}
+
+	var job struct {
+		Status  JobStatus `json:"status"`
+		AgentID string    `json:"agentId"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+		return false, fmt.Errorf("failed to decode job response: %w", err)
+	}
+	return job.Status == JobStatusProcessing && job.AgentID == agentID, nil
+}
+
 // CompleteJob marks a job as completed with results
-func (c *HTTPFulcrumClient) CompleteJob(jobID string, response any) error {
+func (c *HTTPFulcrumClient) CompleteJob(ctx context.Context, jobID string, response any) error {
 	reqBody, err := json.Marshal(response)
 	if err != nil {
 		return fmt.Errorf("failed to marshal job completion request: %w", err)
 	}
 
-	resp, err := c.post(fmt.Sprintf("/api/v1/jobs/%s/complete", jobID), reqBody)
+	resp, err := c.post(ctx, fmt.Sprintf("/api/v1/jobs/%s/complete", jobID), reqBody)
 	if err != nil {
 		return fmt.Errorf("failed to complete job: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("failed to complete job, status: %d", resp.StatusCode)
+		return fmt.Errorf("failed to complete job: %w", newHTTPError(resp))
 	}
 
 	return nil
 }
 
 // FailJob marks a job as failed with an error message
-func (c *HTTPFulcrumClient) FailJob(jobID string, errorMessage string) error {
+func (c *HTTPFulcrumClient) FailJob(ctx context.Context, jobID string, errorMessage string) error {
 	reqBody, err := json.Marshal(map[string]any{
 		"errorMessage": errorMessage,
 	})
@@ -218,99 +401,201 @@ func (c *HTTPFulcrumClient) FailJob(jobID string, errorMessage string) error {
 		return fmt.Errorf("failed to marshal job failure request: %w", err)
 	}
 
-	resp, err := c.post(fmt.Sprintf("/api/v1/jobs/%s/fail", jobID), reqBody)
+	resp, err := c.post(ctx, fmt.Sprintf("/api/v1/jobs/%s/fail", jobID), reqBody)
 	if err != nil {
 		return fmt.Errorf("failed to mark job as failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("failed to mark job as failed, status: %d", resp.StatusCode)
+		return fmt.Errorf("failed to mark job as failed: %w", newHTTPError(resp))
 	}
 
 	return nil
 }
 
-// ReportMetrics sends collected metrics to Fulcrum Core
-func (c *HTTPFulcrumClient) ReportMetric(metric *MetricEntry) error {
+// ReportMetric sends a metric entry to Fulcrum Core
+func (c *HTTPFulcrumClient) ReportMetric(ctx context.Context, metric *MetricEntry) error {
 	reqBody, err := json.Marshal(metric)
 	if err != nil {
 		return fmt.Errorf("failed to marshal metrics request: %w", err)
 	}
 
-	resp, err := c.post("/api/v1/metric-entries", reqBody)
+	resp, err := c.postOnce(ctx, "/api/v1/metric-entries", reqBody)
 	if err != nil {
 		return fmt.Errorf("failed to report metrics: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return fmt.Errorf("failed to report metrics, status: %d", resp.StatusCode)
+		return fmt.Errorf("failed to report metrics: %w", newHTTPError(resp))
 	}
 
 	return nil
 }
 
-// Helper methods for HTTP requests
-func (c *HTTPFulcrumClient) get(endpoint string) (*http.Response, error) {
-	u, err := url.Parse(c.baseURL)
+// ReportMetrics sends metric entries to Fulcrum Core through its batch endpoint, POST /api/v1/metric-entries/batch, in
+// requests of up to the client's metrics batch size. The endpoint returns the status of each entry it did not accept;
+// all other entries were accepted. If some entries are not accepted, a *MetricsError identifying them is returned.
+//
+// If Fulcrum Core does not provide the batch endpoint, entries are submitted with one request per entry instead, at
+// most the client's metrics concurrency at a time.
+func (c *HTTPFulcrumClient) ReportMetrics(ctx context.Context, batch []*MetricEntry) error {
+	errs := make([]error, len(batch))
+	size := max(c.metricsBatchSize, 1)
+	for start := 0; start < len(batch); start += size {
+		end := min(start+size, len(batch))
+		if c.metricsBatchUnsupported.Load() || !c.reportMetricsBatch(ctx, batch[start:end], errs[start:end]) {
+			c.reportEachMetric(ctx, batch[start:end], errs[start:end])
+		}
+	}
+	return newMetricsError(batch, errs)
+}
+
+// metricsBatchResult is the status of an entry of a batch as returned by the Fulcrum Core batch endpoint
+type metricsBatchResult struct {
+	Index  int    `json:"index"`
+	Status int    `json:"status"`
+	Error  string `json:"error"`
+}
+
+// reportMetricsBatch submits the entries in a single request and records the error of each entry in errs. It returns
+// false if Fulcrum Core does not provide the batch endpoint.
+func (c *HTTPFulcrumClient) reportMetricsBatch(ctx context.Context, batch []*MetricEntry, errs []error) bool {
+	fail := func(err error) bool {
+		for i := range errs {
+			errs[i] = err
+		}
+		return true
+	}
+	reqBody, err := json.Marshal(map[string]any{"entries": batch})
 	if err != nil {
-		return nil, err
+		return fail(fmt.Errorf("failed to marshal metrics request: %w", err))
 	}
-	u.Path = path.Join(u.Path, endpoint)
 
-	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	resp, err := c.postOnce(ctx, "/api/v1/metric-entries/batch", reqBody)
 	if err != nil {
-		return nil, err
+		return fail(fmt.Errorf("failed to report metrics: %w", err))
 	}
-	fmt.Println("******" + c.getToken())
-	req.Header.Set("Authorization", "Bearer "+c.getToken())
-	req.Header.Set("Content-Type", "application/json")
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotFound, http.StatusMethodNotAllowed:
+		c.metricsBatchUnsupported.Store(true)
+		return false
+	case http.StatusOK, http.StatusCreated, http.StatusMultiStatus:
+	default:
+		return fail(fmt.Errorf("failed to report metrics: %w", newHTTPError(resp)))
+	}
+
+	var result struct {
+		Results []metricsBatchResult `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil && !errors.Is(err, io.EOF) {
+		return fail(fmt.Errorf("failed to decode metrics response: %w", err))
+	}
+	for _, entry := range result.Results {
+		if entry.Index < 0 || entry.Index >= len(batch) || (entry.Status >= 200 && entry.Status < 300) {
+			continue
+		}
+		errs[entry.Index] = fmt.Errorf("failed to report metrics: %w", &HTTPError{
+			Method:     http.MethodPost,
+			URL:        resp.Request.URL.Redacted(),
+			StatusCode: entry.Status,
+			Body:       entry.Error,
+			Retryable:  isRetryableStatus(entry.Status),
+		})
+	}
+	return true
+}
 
-	return c.httpClient.Do(req)
+// reportEachMetric submits the entries with one request per entry, at most the client's metrics concurrency at a
+// time, and records the error of each entry in errs
+func (c *HTTPFulcrumClient) reportEachMetric(ctx context.Context, batch []*MetricEntry, errs []error) {
+	sem := make(chan struct{}, max(c.metricsConcurrency, 1))
+	var wg sync.WaitGroup
+	for i, entry := range batch {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = fmt.Errorf("failed to report metrics: %w", ctx.Err())
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			errs[i] = c.ReportMetric(ctx, entry)
+		}()
+	}
+	wg.Wait()
 }
 
-func (c *HTTPFulcrumClient) post(endpoint string, body []byte) (*http.Response, error) {
-	u, err := url.Parse(c.baseURL)
+// Helper methods for HTTP requests. GET, PUT and POST requests to job endpoints are retried according to the
+// client's retry policy; postOnce is used for requests that must not be repeated.
+func (c *HTTPFulcrumClient) get(ctx context.Context, endpoint string) (*http.Response, error) {
+	return c.request(ctx, c.retryClient, http.MethodGet, endpoint, nil)
+}
+
+func (c *HTTPFulcrumClient) post(ctx context.Context, endpoint string, body []byte) (*http.Response, error) {
+	return c.request(ctx, c.retryClient, http.MethodPost, endpoint, body)
+}
+
+func (c *HTTPFulcrumClient) postOnce(ctx context.Context, endpoint string, body []byte) (*http.Response, error) {
+	return c.request(ctx, c.httpClient, http.MethodPost, endpoint, body)
+}
+
+func (c *HTTPFulcrumClient) put(ctx context.Context, endpoint string, body []byte) (*http.Response, error) {
+	return c.request(ctx, c.retryClient, http.MethodPut, endpoint, body)
+}
+
+func (c *HTTPFulcrumClient) request(ctx context.Context, httpClient *http.Client, method string, endpoint string, body []byte) (*http.Response, error) {
+	u, err := c.endpointURL(endpoint)
 	if err != nil {
 		return nil, err
 	}
-	u.Path = path.Join(u.Path, endpoint)
 
-	req, err := http.NewRequest(http.MethodPost, u.String(), bytes.NewBuffer(body))
+	token, err := c.auth.Token(ctx)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to obtain token: %w", err)
+	}
+	resp, err := c.send(ctx, httpClient, method, u, body, token)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.getToken())
-
-	req.Header.Set("Content-Type", "application/json")
-
-	return c.httpClient.Do(req)
+	// retry once if the authenticator can provide a different token
+	c.auth.Invalidate(token)
+	if newToken, err := c.auth.Token(ctx); err == nil && newToken != token {
+		resp.Body.Close()
+		return c.send(ctx, httpClient, method, u, body, newToken)
+	}
+	return resp, nil
 }
 
-func (c *HTTPFulcrumClient) put(endpoint string, body []byte) (*http.Response, error) {
+func (c *HTTPFulcrumClient) endpointURL(endpoint string) (string, error) {
 	u, err := url.Parse(c.baseURL)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 	u.Path = path.Join(u.Path, endpoint)
+	return u.String(), nil
+}
 
-	req, err := http.NewRequest(http.MethodPut, u.String(), bytes.NewBuffer(body))
+func (c *HTTPFulcrumClient) send(ctx context.Context, httpClient *http.Client, method string, url string, body []byte, token string) (*http.Response, error) {
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.getToken())
+	req.Header.Set("Authorization", "Bearer "+token)
 	req.Header.Set("Content-Type", "application/json")
 
-	return c.httpClient.Do(req)
-}
-
-func (c *HTTPFulcrumClient) getToken() string {
-	tokenPtr := c.token.Load()
-	if tokenPtr == nil {
-		return ""
-	}
-	return *tokenPtr
+	return httpClient.Do(req)
 }
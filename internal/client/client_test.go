@@ -0,0 +1,509 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/metaform/connector-fabric-manager/common/monitor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHTTPFulcrumClient_GetPendingJobs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/jobs/pending", r.URL.Path)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		_ = json.NewEncoder(w).Encode([]map[string]any{{"id": "job-1", "action": "ServiceCreate", "priority": 2}})
+	}))
+	defer server.Close()
+
+	client := NewHTTPFulcrumClient(server.URL, "token", time.Second)
+
+	jobs, err := client.GetPendingJobs(context.Background())
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "job-1", jobs[0].ID)
+	assert.Equal(t, JobActionServiceCreate, jobs[0].Action)
+	assert.Equal(t, 2, jobs[0].Priority)
+}
+
+func TestHTTPFulcrumClient_Cancellation(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	t.Run("default timeout", func(t *testing.T) {
+		client := NewHTTPFulcrumClient(server.URL, "token", 20*time.Millisecond)
+		err := client.ClaimJob(context.Background(), "job-1")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("caller deadline", func(t *testing.T) {
+		client := NewHTTPFulcrumClient(server.URL, "token", time.Hour)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		err := client.ClaimJob(ctx, "job-1")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		client := NewHTTPFulcrumClient(server.URL, "token", time.Hour)
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, cancel)
+		err := client.UpdateAgentStatus(ctx, "Connected")
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestApiClient_GetFromPManagerCancellation(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+	policy := RetryPolicy{MaxRetries: 2, WaitMin: time.Millisecond, WaitMax: time.Millisecond, MaxElapsed: time.Minute}
+
+	t.Run("attempt timeout", func(t *testing.T) {
+		calls.Store(0)
+		apiClient := NewApiClient(server.URL, "", "", WithPManagerRetryPolicy(policy), WithPManagerRequestTimeout(20*time.Millisecond))
+		_, err := apiClient.GetFromPManager(context.Background(), "health")
+		assert.Error(t, err)
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		calls.Store(0)
+		apiClient := NewApiClient(server.URL, "", "", WithPManagerRetryPolicy(policy), WithPManagerRequestTimeout(time.Hour))
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, cancel)
+		_, err := apiClient.GetFromPManager(ctx, "health")
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+}
+
+func TestApiClient_PostToPManagerRetry(t *testing.T) {
+	var calls atomic.Int32
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"deployment-1"}`))
+	}))
+	defer server.Close()
+
+	policy := RetryPolicy{MaxRetries: 2, WaitMin: time.Millisecond, WaitMax: time.Millisecond, MaxElapsed: time.Minute}
+	apiClient := NewApiClient(server.URL, "", "", WithPManagerRetryPolicy(policy))
+	body, err := apiClient.PostToPManager(context.Background(), "deployment", map[string]string{"id": "manifest-1"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"deployment-1"}`, string(body))
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Equal(t, bodies[0], bodies[1], "the manifest is sent again")
+}
+
+func TestHTTPFulcrumClient_Retry(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 3, WaitMin: time.Millisecond, WaitMax: 5 * time.Millisecond, MaxElapsed: time.Second}
+
+	t.Run("retry after", func(t *testing.T) {
+		var calls atomic.Int32
+		var first time.Time
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) == 1 {
+				first = time.Now()
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			assert.GreaterOrEqual(t, time.Since(first), 900*time.Millisecond)
+			_ = json.NewEncoder(w).Encode([]map[string]any{})
+		}))
+		defer server.Close()
+
+		client := NewHTTPFulcrumClient(server.URL, "token", time.Second, WithRetryPolicy(policy))
+		_, err := client.GetPendingJobs(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("retry after is bounded by the time budget", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		budget := RetryPolicy{MaxRetries: 5, WaitMin: time.Millisecond, WaitMax: time.Millisecond, MaxElapsed: 200 * time.Millisecond}
+		client := NewHTTPFulcrumClient(server.URL, "token", time.Second, WithRetryPolicy(budget))
+		start := time.Now()
+		_, err := client.GetPendingJobs(context.Background())
+		assert.True(t, HasStatus(err, http.StatusTooManyRequests))
+		assert.Less(t, time.Since(start), time.Second)
+		assert.Equal(t, int32(2), calls.Load(), "one retry at the end of the budget")
+	})
+
+	t.Run("gives up", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		client := NewHTTPFulcrumClient(server.URL, "token", time.Second, WithRetryPolicy(policy))
+		err := client.UpdateAgentStatus(context.Background(), "Connected")
+		var httpErr *HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusBadGateway, httpErr.StatusCode)
+		assert.True(t, httpErr.Retryable)
+		assert.Equal(t, int32(4), calls.Load())
+	})
+
+	t.Run("metrics are not retried", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		client := NewHTTPFulcrumClient(server.URL, "token", time.Second, WithRetryPolicy(policy))
+		err := client.ReportMetric(context.Background(), &MetricEntry{ExternalID: "ext-1", Value: 1})
+		require.Error(t, err)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+}
+
+func TestHTTPFulcrumClient_ClaimJobConflict(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 3, WaitMin: time.Millisecond, WaitMax: time.Millisecond, MaxElapsed: time.Second}
+	tests := []struct {
+		name         string
+		responseLost bool
+		agentID      string
+		wantErr      bool
+	}{
+		{name: "claimed by another agent", wantErr: true},
+		{name: "first attempt succeeded, response lost", responseLost: true, agentID: "agent-1"},
+		{name: "retried claim taken by another agent", responseLost: true, agentID: "agent-2", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var claims, lookups atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/api/v1/jobs/job-1/claim":
+					if claims.Add(1) == 1 && tt.responseLost {
+						// the claim is accepted but the connection drops before the response is sent
+						conn, _, err := w.(http.Hijacker).Hijack()
+						require.NoError(t, err)
+						_ = conn.Close()
+						return
+					}
+					w.WriteHeader(http.StatusConflict)
+				case "/api/v1/agents/me":
+					_ = json.NewEncoder(w).Encode(map[string]any{"id": "agent-1"})
+				case "/api/v1/jobs/job-1":
+					lookups.Add(1)
+					_ = json.NewEncoder(w).Encode(map[string]any{"id": "job-1", "status": "Processing", "agentId": tt.agentID})
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			client := NewHTTPFulcrumClient(server.URL, "token", time.Second, WithRetryPolicy(policy))
+			err := client.ClaimJob(context.Background(), "job-1")
+			if tt.wantErr {
+				assert.True(t, HasStatus(err, http.StatusConflict))
+			} else {
+				assert.NoError(t, err)
+			}
+			if tt.responseLost {
+				assert.Equal(t, int32(2), claims.Load())
+				assert.Equal(t, int32(1), lookups.Load())
+			} else {
+				assert.Equal(t, int32(0), lookups.Load(), "a conflict on the first attempt is not looked up")
+			}
+		})
+	}
+}
+
+func TestHTTPFulcrumClient_TraceContext(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	client := NewHTTPFulcrumClient(server.URL, "token", time.Second)
+	require.NoError(t, client.ClaimJob(ctx, "0196ad2c-4b2e-7f1a-9c1d-2f3e4a5b6c7d"))
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	span := spans[0]
+	assert.Equal(t, "POST /api/v1/jobs/{id}/claim", span.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+	assert.Equal(t, fmt.Sprintf("00-%s-%s-01", span.SpanContext().TraceID(), span.SpanContext().SpanID()), traceparent)
+}
+
+func TestHTTPFulcrumClient_ReportMetrics(t *testing.T) {
+	var requests []int
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/metric-entries/batch", r.URL.Path)
+		var body struct {
+			Entries []MetricEntry `json:"entries"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		mu.Lock()
+		requests = append(requests, len(body.Entries))
+		mu.Unlock()
+
+		var results []metricsBatchResult
+		for i, entry := range body.Entries {
+			switch entry.ExternalID {
+			case "unknown":
+				results = append(results, metricsBatchResult{Index: i, Status: http.StatusBadRequest, Error: "unknown service"})
+			case "unavailable":
+				results = append(results, metricsBatchResult{Index: i, Status: http.StatusServiceUnavailable})
+			}
+		}
+		w.WriteHeader(http.StatusMultiStatus)
+		_ = json.NewEncoder(w).Encode(map[string]any{"results": results})
+	}))
+	defer server.Close()
+
+	client := NewHTTPFulcrumClient(server.URL, "token", time.Second, WithMetricsBatchSize(4))
+	batch := make([]*MetricEntry, 6)
+	for i := range batch {
+		batch[i] = &MetricEntry{ExternalID: fmt.Sprintf("ext-%d", i), TypeName: "cfm.deployments", Value: 1}
+	}
+	batch[1].ExternalID = "unavailable"
+	batch[5].ExternalID = "unknown"
+
+	err := client.ReportMetrics(context.Background(), batch)
+	var metricsErr *MetricsError
+	require.ErrorAs(t, err, &metricsErr)
+	assert.Equal(t, 6, metricsErr.Total)
+	require.Len(t, metricsErr.Failures, 2)
+	assert.Equal(t, 1, metricsErr.Failures[0].Index)
+	var httpErr *HTTPError
+	require.ErrorAs(t, metricsErr.Failures[0].Err, &httpErr)
+	assert.True(t, httpErr.Retryable)
+	assert.Equal(t, 5, metricsErr.Failures[1].Index)
+	assert.True(t, HasStatus(metricsErr.Failures[1].Err, http.StatusBadRequest))
+	assert.Equal(t, []int{4, 2}, requests, "entries are submitted in chunks of the batch size")
+
+	assert.NoError(t, client.ReportMetrics(context.Background(), batch[2:5]))
+}
+
+func TestHTTPFulcrumClient_ReportMetricsWithoutBatchEndpoint(t *testing.T) {
+	var inFlight, maxInFlight, batchCalls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/metric-entries/batch" {
+			batchCalls.Add(1)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			peak := maxInFlight.Load()
+			if current <= peak || maxInFlight.CompareAndSwap(peak, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		var entry MetricEntry
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&entry))
+		if entry.ExternalID == "unknown" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := NewHTTPFulcrumClient(server.URL, "token", time.Second, WithMetricsConcurrency(2))
+	batch := make([]*MetricEntry, 6)
+	for i := range batch {
+		batch[i] = &MetricEntry{ExternalID: fmt.Sprintf("ext-%d", i), TypeName: "cfm.deployments", Value: 1}
+	}
+	batch[3].ExternalID = "unknown"
+
+	err := client.ReportMetrics(context.Background(), batch)
+	var metricsErr *MetricsError
+	require.ErrorAs(t, err, &metricsErr)
+	assert.Equal(t, 6, metricsErr.Total)
+	require.Len(t, metricsErr.Failures, 1)
+	assert.Equal(t, 3, metricsErr.Failures[0].Index)
+	assert.True(t, HasStatus(err, http.StatusBadRequest))
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(2))
+
+	assert.NoError(t, client.ReportMetrics(context.Background(), batch[:3]))
+	assert.Equal(t, int32(1), batchCalls.Load(), "the batch endpoint is not tried again")
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	wait, ok := retryAfter("3", now)
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, wait)
+
+	wait, ok = retryAfter(now.Add(10*time.Second).Format(http.TimeFormat), now)
+	assert.True(t, ok)
+	assert.Equal(t, 10*time.Second, wait)
+
+	_, ok = retryAfter("soon", now)
+	assert.False(t, ok)
+}
+
+func TestHTTPFulcrumClient_HTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(`{"title":"Invalid job","status":422,"detail":"job is not pending"}`))
+	}))
+	defer server.Close()
+
+	client := NewHTTPFulcrumClient(server.URL, "token", time.Second)
+	err := client.FailJob(context.Background(), "job-1", "failed")
+
+	var httpErr *HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, http.MethodPost, httpErr.Method)
+	assert.Equal(t, server.URL+"/api/v1/jobs/job-1/fail", httpErr.URL)
+	assert.Equal(t, http.StatusUnprocessableEntity, httpErr.StatusCode)
+	assert.False(t, httpErr.Retryable)
+	require.NotNil(t, httpErr.Problem)
+	assert.Equal(t, "job is not pending", httpErr.Problem.Detail)
+	assert.Contains(t, err.Error(), "Invalid job: job is not pending")
+}
+
+func TestIsRetryableStatus(t *testing.T) {
+	for _, status := range []int{http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusServiceUnavailable} {
+		assert.True(t, isRetryableStatus(status), status)
+	}
+	for _, status := range []int{http.StatusBadRequest, http.StatusConflict, http.StatusNotImplemented} {
+		assert.False(t, isRetryableStatus(status), status)
+	}
+}
+
+func TestHTTPFulcrumClient_RequestLogging(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "agent-1", "token": "agent-secret"})
+	}))
+	defer server.Close()
+
+	logMonitor := &recordingMonitor{}
+	policy := RetryPolicy{MaxRetries: 1, WaitMin: time.Millisecond, WaitMax: time.Millisecond, MaxElapsed: time.Second}
+	client := NewHTTPFulcrumClient(server.URL, "bearer-secret", time.Second, WithRetryPolicy(policy), WithRequestLogging(logMonitor))
+
+	info, err := client.GetAgentInfo(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "agent-secret", info["token"])
+
+	logs := logMonitor.String()
+	assert.NotContains(t, logs, "bearer-secret")
+	assert.NotContains(t, logs, "agent-secret")
+	assert.Contains(t, logs, "Authorization: Bearer ******")
+	assert.Contains(t, logs, "GET /api/v1/agents/me status=503")
+	assert.Contains(t, logs, "GET /api/v1/agents/me status=200")
+}
+
+func TestEndpointTemplate(t *testing.T) {
+	tests := map[string]string{
+		"/api/v1/jobs/pending":                             "/api/v1/jobs/pending",
+		"/api/v1/agents/me/status":                         "/api/v1/agents/me/status",
+		"/api/v1/jobs/job-123/claim":                       "/api/v1/jobs/{id}/claim",
+		"/deployment/0f8fad5b-d9cb-469f-a165-70867728950e": "/deployment/{id}",
+		"/usage/" + url.PathEscape("tenant-a/service-42"):  "/usage/{id}",
+	}
+	for path, expected := range tests {
+		assert.Equal(t, expected, EndpointTemplate(path), path)
+	}
+}
+
+func TestRedactJSON(t *testing.T) {
+	body := []byte(`{"token":"t","nested":{"client_secret":"s","name":"n"},"items":[{"accessToken":"a"}]}`)
+	assert.JSONEq(t,
+		`{"token":"******","nested":{"client_secret":"******","name":"n"},"items":[{"accessToken":"******"}]}`,
+		string(redactJSON(body)))
+	assert.Equal(t, "<9 bytes>", string(redactJSON([]byte("not json!"))))
+}
+
+// recordingMonitor records debug messages
+type recordingMonitor struct {
+	monitor.NoopMonitor
+	mu       sync.Mutex
+	messages []string
+}
+
+func (m *recordingMonitor) Debugf(message string, args ...any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, fmt.Sprintf(message, args...))
+}
+
+func (m *recordingMonitor) String() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return strings.Join(m.messages, "\n")
+}
@@ -0,0 +1,147 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package client
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBody limits how much of an error response body is read
+const maxErrorBody = 64 * 1024
+
+// ProblemDetails is the decoded body of an error response. Services returning RFC 9457 problem documents populate
+// the standard fields; a plain {"error": "..."} or {"message": "..."} body is mapped to Detail.
+type ProblemDetails struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Status   int    `json:"status,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+}
+
+// HTTPError is returned when an upstream request completes with an unexpected status code
+type HTTPError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Problem    *ProblemDetails // Decoded response body, nil if the body is not JSON
+	Body       string          // Raw response body
+	Retryable  bool            // True if repeating the request may succeed
+}
+
+func (e *HTTPError) Error() string {
+	msg := fmt.Sprintf("%s %s failed with status %d", e.Method, e.URL, e.StatusCode)
+	switch {
+	case e.Problem != nil && e.Problem.Title != "" && e.Problem.Detail != "":
+		return fmt.Sprintf("%s: %s: %s", msg, e.Problem.Title, e.Problem.Detail)
+	case e.Problem != nil && e.Problem.Detail != "":
+		return fmt.Sprintf("%s: %s", msg, e.Problem.Detail)
+	case e.Problem != nil && e.Problem.Title != "":
+		return fmt.Sprintf("%s: %s", msg, e.Problem.Title)
+	case e.Body != "":
+		return fmt.Sprintf("%s: %s", msg, e.Body)
+	}
+	return msg
+}
+
+// HasStatus returns true if err is an HTTPError with the given status code
+func HasStatus(err error, status int) bool {
+	var httpErr *HTTPError
+	return errors.As(err, &httpErr) && httpErr.StatusCode == status
+}
+
+// newHTTPError creates an error for the response, consuming its body
+func newHTTPError(resp *http.Response) *HTTPError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	return newHTTPErrorWithBody(resp, body)
+}
+
+// newHTTPErrorWithBody creates an error for the response from an already read body
+func newHTTPErrorWithBody(resp *http.Response, body []byte) *HTTPError {
+	httpErr := &HTTPError{
+		StatusCode: resp.StatusCode,
+		Body:       strings.TrimSpace(string(body)),
+		Retryable:  isRetryableStatus(resp.StatusCode),
+	}
+	if resp.Request != nil {
+		httpErr.Method = resp.Request.Method
+		httpErr.URL = resp.Request.URL.Redacted()
+	}
+
+	var problem struct {
+		ProblemDetails
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &problem); err == nil {
+		details := problem.ProblemDetails
+		if details.Detail == "" {
+			details.Detail = cmp.Or(problem.Error, problem.Message)
+		}
+		httpErr.Problem = &details
+	}
+	return httpErr
+}
+
+// isRetryableStatus returns true for 408, 429 and 5xx responses except 501, as an unimplemented operation does not
+// become available by trying again
+func isRetryableStatus(status int) bool {
+	return status == http.StatusRequestTimeout || status == http.StatusTooManyRequests ||
+		(status >= 500 && status != http.StatusNotImplemented)
+}
+
+// MetricFailure is a metric entry of a batch that was not accepted by Fulcrum Core
+type MetricFailure struct {
+	Index int // Position of the entry in the batch
+	Entry *MetricEntry
+	Err   error
+}
+
+// MetricsError is returned when some entries of a metrics batch were not accepted
+type MetricsError struct {
+	Total    int
+	Failures []MetricFailure
+}
+
+func (e *MetricsError) Error() string {
+	return fmt.Sprintf("%d of %d metric entries not accepted, first error: %v", len(e.Failures), e.Total, e.Failures[0].Err)
+}
+
+// Unwrap returns the errors of the failed entries, so errors.As and errors.Is match any of them
+func (e *MetricsError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, failure := range e.Failures {
+		errs[i] = failure.Err
+	}
+	return errs
+}
+
+// newMetricsError returns a *MetricsError for the entries with a non-nil error, or nil if all entries were accepted
+func newMetricsError(batch []*MetricEntry, errs []error) error {
+	var failures []MetricFailure
+	for i, err := range errs {
+		if err != nil {
+			failures = append(failures, MetricFailure{Index: i, Entry: batch[i], Err: err})
+		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	return &MetricsError{Total: len(batch), Failures: failures}
+}
@@ -0,0 +1,172 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/metaform/connector-fabric-manager/common/monitor"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	redacted = "******"
+	// maxLoggedBody limits how much of a request or response body is logged
+	maxLoggedBody = 4 * 1024
+)
+
+// sensitiveHeaders are never logged in clear text
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+	"X-Api-Key":           true,
+}
+
+// sensitiveFields are substrings of JSON field names whose values are never logged in clear text
+var sensitiveFields = []string{"token", "secret", "password", "apikey", "api_key", "credential"}
+
+// loggingTransport logs every request attempt at debug level with secrets masked
+type loggingTransport struct {
+	next    http.RoundTripper
+	name    string
+	monitor monitor.LogMonitor
+}
+
+func newLoggingTransport(next http.RoundTripper, name string, monitor monitor.LogMonitor) http.RoundTripper {
+	return &loggingTransport{next: next, name: name, monitor: monitor}
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.monitor.Debugf("%s request %s %s headers=%s body=%s",
+		t.name, req.Method, req.URL.Path, formatHeaders(req.Header), t.requestBody(req))
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	latency := time.Since(start)
+	if err != nil {
+		t.monitor.Debugf("%s request %s %s failed after %s: %v", t.name, req.Method, req.URL.Path, latency, err)
+		return nil, err
+	}
+
+	t.monitor.Debugf("%s response %s %s status=%d latency=%s body=%s",
+		t.name, req.Method, req.URL.Path, resp.StatusCode, latency, t.responseBody(resp))
+	return resp, nil
+}
+
+// requestBody returns the redacted request body without consuming it
+func (t *loggingTransport) requestBody(req *http.Request) string {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody == nil {
+		return ""
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
+	data, _ := io.ReadAll(io.LimitReader(body, maxLoggedBody))
+	return string(redactJSON(data))
+}
+
+// responseBody returns the redacted start of the response body and restores the body for the caller
+func (t *loggingTransport) responseBody(resp *http.Response) string {
+	if resp.Body == nil || resp.Body == http.NoBody {
+		return ""
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxLoggedBody))
+	resp.Body = &prefixedBody{Reader: io.MultiReader(bytes.NewReader(data), resp.Body), Closer: resp.Body}
+	return string(redactJSON(data))
+}
+
+type prefixedBody struct {
+	io.Reader
+	io.Closer
+}
+
+// formatHeaders renders headers in a stable order with sensitive values masked
+func formatHeaders(header http.Header) string {
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		value := strings.Join(header[key], ",")
+		if sensitiveHeaders[http.CanonicalHeaderKey(key)] {
+			value = redactHeaderValue(value)
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s", key, value))
+	}
+	return "[" + strings.Join(parts, "; ") + "]"
+}
+
+// redactHeaderValue masks a header value, keeping the authorization scheme if present
+func redactHeaderValue(value string) string {
+	if scheme, _, found := strings.Cut(value, " "); found {
+		return scheme + " " + redacted
+	}
+	return redacted
+}
+
+// redactJSON masks the values of sensitive fields in a JSON document. Bodies that are not valid JSON are replaced
+// entirely, since they cannot be inspected.
+func redactJSON(data []byte) []byte {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return []byte(fmt.Sprintf("<%d bytes>", len(data)))
+	}
+	result, err := json.Marshal(redactValue(doc))
+	if err != nil {
+		return []byte(redacted)
+	}
+	return result
+}
+
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if isSensitiveField(key) {
+				v[key] = redacted
+			} else {
+				v[key] = redactValue(field)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+	return value
+}
+
+func isSensitiveField(name string) bool {
+	name = strings.ToLower(name)
+	for _, field := range sensitiveFields {
+		if strings.Contains(name, field) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,543 @@
+// Code generated by mockery v2.53.7. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	client "github.com/metaform/cfm-fulcrum/internal/client"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// FulcrumClient is an autogenerated mock type for the FulcrumClient type
+type FulcrumClient struct {
+	mock.Mock
+}
+
+type FulcrumClient_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *FulcrumClient) EXPECT() *FulcrumClient_Expecter {
+	return &FulcrumClient_Expecter{mock: &_m.Mock}
+}
+
+// ClaimJob provides a mock function with given fields: ctx, jobID
+func (_m *FulcrumClient) ClaimJob(ctx context.Context, jobID string) error {
+	ret := _m.Called(ctx, jobID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimJob")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, jobID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FulcrumClient_ClaimJob_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ClaimJob'
+type FulcrumClient_ClaimJob_Call struct {
+	*mock.Call
+}
+
+// ClaimJob is a helper method to define mock.On call
+//   - ctx context.Context
+//   - jobID string
+func (_e *FulcrumClient_Expecter) ClaimJob(ctx interface{}, jobID interface{}) *FulcrumClient_ClaimJob_Call {
+	return &FulcrumClient_ClaimJob_Call{Call: _e.mock.On("ClaimJob", ctx, jobID)}
+}
+
+func (_c *FulcrumClient_ClaimJob_Call) Run(run func(ctx context.Context, jobID string)) *FulcrumClient_ClaimJob_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *FulcrumClient_ClaimJob_Call) Return(_a0 error) *FulcrumClient_ClaimJob_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *FulcrumClient_ClaimJob_Call) RunAndReturn(run func(context.Context, string) error) *FulcrumClient_ClaimJob_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CompleteJob provides a mock function with given fields: ctx, jobID, resources
+func (_m *FulcrumClient) CompleteJob(ctx context.Context, jobID string, resources interface{}) error {
+	ret := _m.Called(ctx, jobID, resources)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CompleteJob")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, interface{}) error); ok {
+		r0 = rf(ctx, jobID, resources)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FulcrumClient_CompleteJob_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CompleteJob'
+type FulcrumClient_CompleteJob_Call struct {
+	*mock.Call
+}
+
+// CompleteJob is a helper method to define mock.On call
+//   - ctx context.Context
+//   - jobID string
+//   - resources interface{}
+func (_e *FulcrumClient_Expecter) CompleteJob(ctx interface{}, jobID interface{}, resources interface{}) *FulcrumClient_CompleteJob_Call {
+	return &FulcrumClient_CompleteJob_Call{Call: _e.mock.On("CompleteJob", ctx, jobID, resources)}
+}
+
+func (_c *FulcrumClient_CompleteJob_Call) Run(run func(ctx context.Context, jobID string, resources interface{})) *FulcrumClient_CompleteJob_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(interface{}))
+	})
+	return _c
+}
+
+func (_c *FulcrumClient_CompleteJob_Call) Return(_a0 error) *FulcrumClient_CompleteJob_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *FulcrumClient_CompleteJob_Call) RunAndReturn(run func(context.Context, string, interface{}) error) *FulcrumClient_CompleteJob_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FailJob provides a mock function with given fields: ctx, jobID, errorMessage
+func (_m *FulcrumClient) FailJob(ctx context.Context, jobID string, errorMessage string) error {
+	ret := _m.Called(ctx, jobID, errorMessage)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FailJob")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, jobID, errorMessage)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FulcrumClient_FailJob_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FailJob'
+type FulcrumClient_FailJob_Call struct {
+	*mock.Call
+}
+
+// FailJob is a helper method to define mock.On call
+//   - ctx context.Context
+//   - jobID string
+//   - errorMessage string
+func (_e *FulcrumClient_Expecter) FailJob(ctx interface{}, jobID interface{}, errorMessage interface{}) *FulcrumClient_FailJob_Call {
+	return &FulcrumClient_FailJob_Call{Call: _e.mock.On("FailJob", ctx, jobID, errorMessage)}
+}
+
+func (_c *FulcrumClient_FailJob_Call) Run(run func(ctx context.Context, jobID string, errorMessage string)) *FulcrumClient_FailJob_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *FulcrumClient_FailJob_Call) Return(_a0 error) *FulcrumClient_FailJob_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *FulcrumClient_FailJob_Call) RunAndReturn(run func(context.Context, string, string) error) *FulcrumClient_FailJob_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAgentInfo provides a mock function with given fields: ctx
+func (_m *FulcrumClient) GetAgentInfo(ctx context.Context) (map[string]interface{}, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAgentInfo")
+	}
+
+	var r0 map[string]interface{}
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (map[string]interface{}, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) map[string]interface{}); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]interface{})
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FulcrumClient_GetAgentInfo_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAgentInfo'
+type FulcrumClient_GetAgentInfo_Call struct {
+	*mock.Call
+}
+
+// GetAgentInfo is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *FulcrumClient_Expecter) GetAgentInfo(ctx interface{}) *FulcrumClient_GetAgentInfo_Call {
+	return &FulcrumClient_GetAgentInfo_Call{Call: _e.mock.On("GetAgentInfo", ctx)}
+}
+
+func (_c *FulcrumClient_GetAgentInfo_Call) Run(run func(ctx context.Context)) *FulcrumClient_GetAgentInfo_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *FulcrumClient_GetAgentInfo_Call) Return(_a0 map[string]interface{}, _a1 error) *FulcrumClient_GetAgentInfo_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *FulcrumClient_GetAgentInfo_Call) RunAndReturn(run func(context.Context) (map[string]interface{}, error)) *FulcrumClient_GetAgentInfo_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetPendingJobs provides a mock function with given fields: ctx
+func (_m *FulcrumClient) GetPendingJobs(ctx context.Context) ([]*client.Job, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPendingJobs")
+	}
+
+	var r0 []*client.Job
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*client.Job, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*client.Job); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*client.Job)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FulcrumClient_GetPendingJobs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPendingJobs'
+type FulcrumClient_GetPendingJobs_Call struct {
+	*mock.Call
+}
+
+// GetPendingJobs is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *FulcrumClient_Expecter) GetPendingJobs(ctx interface{}) *FulcrumClient_GetPendingJobs_Call {
+	return &FulcrumClient_GetPendingJobs_Call{Call: _e.mock.On("GetPendingJobs", ctx)}
+}
+
+func (_c *FulcrumClient_GetPendingJobs_Call) Run(run func(ctx context.Context)) *FulcrumClient_GetPendingJobs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *FulcrumClient_GetPendingJobs_Call) Return(_a0 []*client.Job, _a1 error) *FulcrumClient_GetPendingJobs_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *FulcrumClient_GetPendingJobs_Call) RunAndReturn(run func(context.Context) ([]*client.Job, error)) *FulcrumClient_GetPendingJobs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReportMetric provides a mock function with given fields: ctx, metrics
+func (_m *FulcrumClient) ReportMetric(ctx context.Context, metrics *client.MetricEntry) error {
+	ret := _m.Called(ctx, metrics)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReportMetric")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *client.MetricEntry) error); ok {
+		r0 = rf(ctx, metrics)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FulcrumClient_ReportMetric_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReportMetric'
+type FulcrumClient_ReportMetric_Call struct {
+	*mock.Call
+}
+
+// ReportMetric is a helper method to define mock.On call
+//   - ctx context.Context
+//   - metrics *client.MetricEntry
+func (_e *FulcrumClient_Expecter) ReportMetric(ctx interface{}, metrics interface{}) *FulcrumClient_ReportMetric_Call {
+	return &FulcrumClient_ReportMetric_Call{Call: _e.mock.On("ReportMetric", ctx, metrics)}
+}
+
+func (_c *FulcrumClient_ReportMetric_Call) Run(run func(ctx context.Context, metrics *client.MetricEntry)) *FulcrumClient_ReportMetric_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*client.MetricEntry))
+	})
+	return _c
+}
+
+func (_c *FulcrumClient_ReportMetric_Call) Return(_a0 error) *FulcrumClient_ReportMetric_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *FulcrumClient_ReportMetric_Call) RunAndReturn(run func(context.Context, *client.MetricEntry) error) *FulcrumClient_ReportMetric_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReportMetrics provides a mock function with given fields: ctx, batch
+func (_m *FulcrumClient) ReportMetrics(ctx context.Context, batch []*client.MetricEntry) error {
+	ret := _m.Called(ctx, batch)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReportMetrics")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*client.MetricEntry) error); ok {
+		r0 = rf(ctx, batch)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FulcrumClient_ReportMetrics_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReportMetrics'
+type FulcrumClient_ReportMetrics_Call struct {
+	*mock.Call
+}
+
+// ReportMetrics is a helper method to define mock.On call
+//   - ctx context.Context
+//   - batch []*client.MetricEntry
+func (_e *FulcrumClient_Expecter) ReportMetrics(ctx interface{}, batch interface{}) *FulcrumClient_ReportMetrics_Call {
+	return &FulcrumClient_ReportMetrics_Call{Call: _e.mock.On("ReportMetrics", ctx, batch)}
+}
+
+func (_c *FulcrumClient_ReportMetrics_Call) Run(run func(ctx context.Context, batch []*client.MetricEntry)) *FulcrumClient_ReportMetrics_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]*client.MetricEntry))
+	})
+	return _c
+}
+
+func (_c *FulcrumClient_ReportMetrics_Call) Return(_a0 error) *FulcrumClient_ReportMetrics_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *FulcrumClient_ReportMetrics_Call) RunAndReturn(run func(context.Context, []*client.MetricEntry) error) *FulcrumClient_ReportMetrics_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateAgentStatus provides a mock function with given fields: ctx, status
+func (_m *FulcrumClient) UpdateAgentStatus(ctx context.Context, status string) error {
+	ret := _m.Called(ctx, status)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateAgentStatus")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, status)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FulcrumClient_UpdateAgentStatus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateAgentStatus'
+type FulcrumClient_UpdateAgentStatus_Call struct {
+	*mock.Call
+}
+
+// UpdateAgentStatus is a helper method to define mock.On call
+//   - ctx context.Context
+//   - status string
+func (_e *FulcrumClient_Expecter) UpdateAgentStatus(ctx interface{}, status interface{}) *FulcrumClient_UpdateAgentStatus_Call {
+	return &FulcrumClient_UpdateAgentStatus_Call{Call: _e.mock.On("UpdateAgentStatus", ctx, status)}
+}
+
+func (_c *FulcrumClient_UpdateAgentStatus_Call) Run(run func(ctx context.Context, status string)) *FulcrumClient_UpdateAgentStatus_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *FulcrumClient_UpdateAgentStatus_Call) Return(_a0 error) *FulcrumClient_UpdateAgentStatus_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *FulcrumClient_UpdateAgentStatus_Call) RunAndReturn(run func(context.Context, string) error) *FulcrumClient_UpdateAgentStatus_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateToken provides a mock function with given fields: token
+func (_m *FulcrumClient) UpdateToken(token string) error {
+	ret := _m.Called(token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateToken")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FulcrumClient_UpdateToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateToken'
+type FulcrumClient_UpdateToken_Call struct {
+	*mock.Call
+}
+
+// UpdateToken is a helper method to define mock.On call
+//   - token string
+func (_e *FulcrumClient_Expecter) UpdateToken(token interface{}) *FulcrumClient_UpdateToken_Call {
+	return &FulcrumClient_UpdateToken_Call{Call: _e.mock.On("UpdateToken", token)}
+}
+
+func (_c *FulcrumClient_UpdateToken_Call) Run(run func(token string)) *FulcrumClient_UpdateToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *FulcrumClient_UpdateToken_Call) Return(_a0 error) *FulcrumClient_UpdateToken_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *FulcrumClient_UpdateToken_Call) RunAndReturn(run func(string) error) *FulcrumClient_UpdateToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ValidateToken provides a mock function with given fields: ctx, token
+func (_m *FulcrumClient) ValidateToken(ctx context.Context, token string) (map[string]interface{}, error) {
+	ret := _m.Called(ctx, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ValidateToken")
+	}
+
+	var r0 map[string]interface{}
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (map[string]interface{}, error)); ok {
+		return rf(ctx, token)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) map[string]interface{}); ok {
+		r0 = rf(ctx, token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]interface{})
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FulcrumClient_ValidateToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ValidateToken'
+type FulcrumClient_ValidateToken_Call struct {
+	*mock.Call
+}
+
+// ValidateToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - token string
+func (_e *FulcrumClient_Expecter) ValidateToken(ctx interface{}, token interface{}) *FulcrumClient_ValidateToken_Call {
+	return &FulcrumClient_ValidateToken_Call{Call: _e.mock.On("ValidateToken", ctx, token)}
+}
+
+func (_c *FulcrumClient_ValidateToken_Call) Run(run func(ctx context.Context, token string)) *FulcrumClient_ValidateToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *FulcrumClient_ValidateToken_Call) Return(_a0 map[string]interface{}, _a1 error) *FulcrumClient_ValidateToken_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *FulcrumClient_ValidateToken_Call) RunAndReturn(run func(context.Context, string) (map[string]interface{}, error)) *FulcrumClient_ValidateToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewFulcrumClient creates a new instance of FulcrumClient. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewFulcrumClient(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *FulcrumClient {
+	mock := &FulcrumClient{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
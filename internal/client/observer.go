@@ -0,0 +1,84 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package client
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Names of the upstream services passed to a RequestObserver
+const (
+	UpstreamFulcrum  = "fulcrum"
+	UpstreamPManager = "pmanager"
+	UpstreamTManager = "tmanager"
+)
+
+// RequestObserver receives the outcome of every request attempt sent to an upstream service, e.g. to record metrics.
+// The endpoint is the request path with IDs replaced by a placeholder. The status is 0 if no response was received.
+type RequestObserver interface {
+	ObserveRequest(upstream string, method string, endpoint string, status int, duration time.Duration)
+}
+
+// observingTransport reports every request attempt to a RequestObserver
+type observingTransport struct {
+	next     http.RoundTripper
+	upstream string
+	observer RequestObserver
+}
+
+// NewObservingTransport returns a transport that reports requests sent to the upstream service to the observer
+func NewObservingTransport(next http.RoundTripper, upstream string, observer RequestObserver) http.RoundTripper {
+	return &observingTransport{next: next, upstream: upstream, observer: observer}
+}
+
+func (t *observingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	status := 0
+	if err == nil {
+		status = resp.StatusCode
+	}
+	t.observer.ObserveRequest(t.upstream, req.Method, EndpointTemplate(req.URL.Path), status, time.Since(start))
+	return resp, err
+}
+
+var (
+	uuidPattern    = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	versionPattern = regexp.MustCompile(`^v[0-9]+$`)
+)
+
+// EndpointTemplate replaces path segments that look like IDs with {id}, so that endpoints can be used as metric labels
+// without unbounded cardinality
+func EndpointTemplate(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if isIDSegment(segment) {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func isIDSegment(segment string) bool {
+	if uuidPattern.MatchString(segment) {
+		return true
+	}
+	if versionPattern.MatchString(segment) {
+		return false
+	}
+	// other segments containing digits are generated IDs rather than resource names
+	return strings.ContainsAny(segment, "0123456789")
+}
@@ -0,0 +1,172 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package client
+
+import (
+	"context"
+	"errors"
+	"github.com/hashicorp/go-retryablehttp"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// RetryPolicy controls how idempotent upstream requests are retried. Connection errors, 429 and 5xx responses (except
+// 501) are retried with exponential backoff and jitter; a Retry-After header on 429 and 503 responses takes precedence
+// over the computed backoff. Waits are cut short at MaxElapsed after the first attempt, and no retry is started once
+// MaxElapsed has passed.
+type RetryPolicy struct {
+	MaxRetries int
+	WaitMin    time.Duration
+	WaitMax    time.Duration
+	MaxElapsed time.Duration
+}
+
+// DefaultRetryPolicy returns the policy used when no retry configuration is given
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: 3,
+		WaitMin:    500 * time.Millisecond,
+		WaitMax:    10 * time.Second,
+		MaxElapsed: time.Minute,
+	}
+}
+
+// Validate checks that the policy values are usable
+func (p RetryPolicy) Validate() error {
+	switch {
+	case p.MaxRetries < 0:
+		return errors.New("max retries must not be negative")
+	case p.WaitMin <= 0:
+		return errors.New("minimum wait must be positive")
+	case p.WaitMax < p.WaitMin:
+		return errors.New("maximum wait must not be less than minimum wait")
+	case p.MaxElapsed <= 0:
+		return errors.New("maximum elapsed time must be positive")
+	}
+	return nil
+}
+
+// newRetryingClient returns an HTTP client that retries requests according to the policy, sending each attempt through
+// the transport. Each attempt is bounded by attemptTimeout if it is positive. Request bodies are buffered so they can
+// be replayed.
+func newRetryingClient(policy RetryPolicy, transport http.RoundTripper, attemptTimeout time.Duration) *http.Client {
+	rc := retryablehttp.NewClient()
+	rc.HTTPClient = &http.Client{Transport: transport, Timeout: attemptTimeout}
+	rc.Logger = nil
+	rc.RetryMax = policy.MaxRetries
+	rc.RetryWaitMin = policy.WaitMin
+	rc.RetryWaitMax = policy.WaitMax
+	rc.Backoff = policy.backoff
+	rc.CheckRetry = policy.checkRetry
+	rc.RequestLogHook = func(_ retryablehttp.Logger, req *http.Request, attempt int) {
+		if attempts, ok := req.Context().Value(attemptsKey{}).(*atomic.Int32); ok {
+			attempts.Store(int32(attempt + 1))
+		}
+	}
+	// return the last response instead of a generic "giving up" error so callers can inspect the status
+	rc.ErrorHandler = retryablehttp.PassthroughErrorHandler
+
+	retrying := &retryablehttp.RoundTripper{Client: rc}
+	return &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			ctx := context.WithValue(req.Context(), retryStartKey{}, time.Now())
+			return retrying.RoundTrip(req.WithContext(ctx))
+		}),
+	}
+}
+
+// checkRetry applies the default retry classification and stops retrying once the policy's time budget is spent
+func (p RetryPolicy) checkRetry(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	retry, checkErr := retryablehttp.DefaultRetryPolicy(ctx, resp, err)
+	if !retry || checkErr != nil {
+		return retry, checkErr
+	}
+	return p.remaining(ctx) > 0, nil
+}
+
+// backoff returns the wait before the next attempt. Retry-After is honored on 429 and 503 responses; otherwise the
+// wait doubles per attempt up to max with the upper half randomized. The wait never exceeds the remaining time budget.
+func (p RetryPolicy) backoff(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+	remaining := p.MaxElapsed
+	if resp != nil && resp.Request != nil {
+		remaining = boundDuration(p.remaining(resp.Request.Context()), 0, p.MaxElapsed)
+	}
+	if resp != nil && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable) {
+		if wait, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			return boundDuration(wait, 0, remaining)
+		}
+	}
+
+	wait := max
+	if attemptNum < 32 {
+		wait = boundDuration(min<<attemptNum, min, max)
+	}
+	half := wait / 2
+	return boundDuration(half+rand.N(wait-half+1), 0, remaining)
+}
+
+// remaining returns the time left of the policy's budget for the request whose context is given
+func (p RetryPolicy) remaining(ctx context.Context) time.Duration {
+	start, ok := ctx.Value(retryStartKey{}).(time.Time)
+	if !ok {
+		return p.MaxElapsed
+	}
+	return p.MaxElapsed - time.Since(start)
+}
+
+// retryAfter parses a Retry-After header given either in seconds or as an HTTP date
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+func boundDuration(d, lower, upper time.Duration) time.Duration {
+	if d < lower {
+		return lower
+	}
+	if d > upper {
+		return upper
+	}
+	return d
+}
+
+type retryStartKey struct{}
+
+type attemptsKey struct{}
+
+// countAttempts returns a context recording the number of attempts a retrying client made for the last request sent
+// with it
+func countAttempts(ctx context.Context) (context.Context, *atomic.Int32) {
+	attempts := &atomic.Int32{}
+	return context.WithValue(ctx, attemptsKey{}, attempts), attempts
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,254 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/metaform/connector-fabric-manager/common/monitor"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultTLSReloadInterval is how often certificate files are checked for changes
+const DefaultTLSReloadInterval = 10 * time.Second
+
+// TLSConfig configures TLS for connections to an upstream service. All fields are optional; without a CA file the
+// system roots are used, and a client certificate is only presented if both certificate and key files are set.
+type TLSConfig struct {
+	CAFile     string
+	CertFile   string
+	KeyFile    string
+	MinVersion string // "1.2" or "1.3"
+	ServerName string // Overrides the host name used for verification and SNI
+}
+
+// IsSet returns true if any TLS setting is configured
+func (c TLSConfig) IsSet() bool {
+	return c != TLSConfig{}
+}
+
+// Validate checks that the settings are consistent
+func (c TLSConfig) Validate() error {
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return errors.New("certificate and key files must be set together")
+	}
+	if _, err := parseTLSVersion(c.MinVersion); err != nil {
+		return err
+	}
+	return nil
+}
+
+// NewTLSTransport returns an HTTP transport using the TLS settings. The CA bundle and client certificate are loaded
+// immediately and reloaded on a later handshake once their files change.
+func NewTLSTransport(config TLSConfig, reloadInterval time.Duration, logMonitor monitor.LogMonitor) (*http.Transport, error) {
+	tlsConfig, reloader, err := newTLSClientConfig(config, reloadInterval, logMonitor)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	if reloader != nil && config.CAFile != "" {
+		transport.DialTLSContext = reloader.dialTLS(tlsConfig)
+	}
+	return transport, nil
+}
+
+func newTLSClientConfig(config TLSConfig, reloadInterval time.Duration, logMonitor monitor.LogMonitor) (*tls.Config, *tlsReloader, error) {
+	if err := config.Validate(); err != nil {
+		return nil, nil, err
+	}
+	minVersion, _ := parseTLSVersion(config.MinVersion)
+	tlsConfig := &tls.Config{
+		MinVersion: minVersion,
+		ServerName: config.ServerName,
+	}
+	if config.CAFile == "" && config.CertFile == "" {
+		return tlsConfig, nil, nil
+	}
+
+	reloader := &tlsReloader{config: config, interval: reloadInterval, monitor: logMonitor}
+	if err := reloader.load(); err != nil {
+		return nil, nil, err
+	}
+	if config.CertFile != "" {
+		tlsConfig.GetClientCertificate = reloader.clientCertificate
+	}
+	if config.CAFile != "" {
+		// Verification against the reloadable CA pool is done in VerifyConnection; the built-in verification only
+		// supports a fixed pool. The connection state lacks the host name for IP addresses, as no SNI is sent, so
+		// connections are dialed by dialTLS, which verifies against the dialed host.
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+			return reloader.verifyConnection(state, state.ServerName)
+		}
+	}
+	return tlsConfig, reloader, nil
+}
+
+// tlsReloader holds the CA pool and client certificate, reloading them when their files change
+type tlsReloader struct {
+	config   TLSConfig
+	interval time.Duration
+	monitor  monitor.LogMonitor
+
+	mu        sync.Mutex
+	checked   time.Time
+	modTimes  map[string]time.Time
+	roots     *x509.CertPool
+	clientKey *tls.Certificate
+}
+
+func (r *tlsReloader) clientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	r.reloadIfChanged()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.clientKey, nil
+}
+
+// dialTLS returns a dial function that verifies the server certificate against the configured server name, or
+// otherwise against the dialed host, including IP addresses
+func (r *tlsReloader) dialTLS(tlsConfig *tls.Config) func(ctx context.Context, network string, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network string, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		serverName := tlsConfig.ServerName
+		if serverName == "" {
+			serverName = host
+		}
+		config := tlsConfig.Clone()
+		config.ServerName = serverName
+		config.VerifyConnection = func(state tls.ConnectionState) error {
+			return r.verifyConnection(state, serverName)
+		}
+		dialer := &tls.Dialer{NetDialer: &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}, Config: config}
+		return dialer.DialContext(ctx, network, addr)
+	}
+}
+
+// verifyConnection verifies the server certificate chain against the CA pool and the server name, which may be a host
+// name or an IP address
+func (r *tlsReloader) verifyConnection(state tls.ConnectionState, serverName string) error {
+	if serverName == "" {
+		return errors.New("no server name to verify the server certificate against")
+	}
+	r.reloadIfChanged()
+	r.mu.Lock()
+	roots := r.roots
+	r.mu.Unlock()
+
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("server presented no certificate")
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := state.PeerCertificates[0].Verify(x509.VerifyOptions{
+		DNSName:       serverName,
+		Roots:         roots,
+		Intermediates: intermediates,
+	})
+	return err
+}
+
+// reloadIfChanged reloads the files if the reload interval has passed and a file was modified. Failed reloads keep
+// the previously loaded material.
+func (r *tlsReloader) reloadIfChanged() {
+	r.mu.Lock()
+	due := time.Since(r.checked) >= r.interval
+	changed := due && r.filesChanged()
+	if due {
+		r.checked = time.Now()
+	}
+	r.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	if err := r.load(); err != nil {
+		r.monitor.Warnf("Unable to reload TLS files, keeping previous certificates: %v", err)
+		return
+	}
+	r.monitor.Infof("Reloaded TLS files")
+}
+
+// filesChanged must be called with the lock held
+func (r *tlsReloader) filesChanged() bool {
+	for file, modTime := range r.modTimes {
+		info, err := os.Stat(file)
+		if err != nil || !info.ModTime().Equal(modTime) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *tlsReloader) load() error {
+	modTimes := make(map[string]time.Time)
+	stat := func(file string) {
+		if info, err := os.Stat(file); err == nil {
+			modTimes[file] = info.ModTime()
+		}
+	}
+
+	var roots *x509.CertPool
+	if r.config.CAFile != "" {
+		stat(r.config.CAFile)
+		pem, err := os.ReadFile(r.config.CAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read CA file: %w", err)
+		}
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in CA file %s", r.config.CAFile)
+		}
+	}
+
+	var clientKey *tls.Certificate
+	if r.config.CertFile != "" {
+		stat(r.config.CertFile)
+		stat(r.config.KeyFile)
+		cert, err := tls.LoadX509KeyPair(r.config.CertFile, r.config.KeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		clientKey = &cert
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.roots = roots
+	r.clientKey = clientKey
+	r.modTimes = modTimes
+	r.checked = time.Now()
+	return nil
+}
+
+func parseTLSVersion(version string) (uint16, error) {
+	switch version {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	}
+	return 0, fmt.Errorf("unsupported minimum TLS version %q, expected 1.2 or 1.3", version)
+}
@@ -0,0 +1,162 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package client
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/metaform/connector-fabric-manager/common/monitor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewTLSTransport_ReloadsCA(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	otherCert, _ := generateCert(t, "other", x509.ExtKeyUsageServerAuth)
+	require.NoError(t, os.WriteFile(caFile, otherCert, 0600))
+
+	transport, err := NewTLSTransport(TLSConfig{CAFile: caFile}, 0, monitor.NoopMonitor{})
+	require.NoError(t, err)
+	httpClient := &http.Client{Transport: transport}
+
+	_, err = httpClient.Get(server.URL)
+	require.Error(t, err, "server certificate must not be trusted")
+
+	serverCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caFile, serverCert, 0600))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(caFile, later, later))
+
+	resp, err := httpClient.Get(server.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+}
+
+func TestNewTLSTransport_ClientCertificate(t *testing.T) {
+	clientCert, clientKey := generateCert(t, "agent", x509.ExtKeyUsageClientAuth)
+	clientCAs := x509.NewCertPool()
+	require.True(t, clientCAs.AppendCertsFromPEM(clientCert))
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	dir := t.TempDir()
+	config := TLSConfig{
+		CAFile:     filepath.Join(dir, "ca.pem"),
+		CertFile:   filepath.Join(dir, "cert.pem"),
+		KeyFile:    filepath.Join(dir, "key.pem"),
+		MinVersion: "1.3",
+	}
+	require.NoError(t, os.WriteFile(config.CAFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600))
+	require.NoError(t, os.WriteFile(config.CertFile, clientCert, 0600))
+	require.NoError(t, os.WriteFile(config.KeyFile, clientKey, 0600))
+
+	transport, err := NewTLSTransport(config, time.Minute, monitor.NoopMonitor{})
+	require.NoError(t, err)
+
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	subject, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "agent", string(subject))
+
+	noCert, err := NewTLSTransport(TLSConfig{CAFile: config.CAFile}, time.Minute, monitor.NoopMonitor{})
+	require.NoError(t, err)
+	_, err = (&http.Client{Transport: noCert}).Get(server.URL)
+	assert.Error(t, err, "server requires a client certificate")
+}
+
+func TestNewTLSTransport_VerifiesHost(t *testing.T) {
+	serverCert, serverKey := generateCert(t, "localhost", x509.ExtKeyUsageServerAuth)
+	keyPair, err := tls.X509KeyPair(serverCert, serverKey)
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{keyPair}}
+	server.StartTLS()
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, serverCert, 0600))
+
+	// The certificate is only valid for localhost, not for the IP address of the server URL
+	transport, err := NewTLSTransport(TLSConfig{CAFile: caFile}, time.Minute, monitor.NoopMonitor{})
+	require.NoError(t, err)
+	_, err = (&http.Client{Transport: transport}).Get(server.URL)
+	assert.ErrorContains(t, err, "127.0.0.1")
+
+	mismatched, err := NewTLSTransport(TLSConfig{CAFile: caFile, ServerName: "other.example"}, time.Minute, monitor.NoopMonitor{})
+	require.NoError(t, err)
+	_, err = (&http.Client{Transport: mismatched}).Get(server.URL)
+	assert.ErrorContains(t, err, "other.example")
+
+	overridden, err := NewTLSTransport(TLSConfig{CAFile: caFile, ServerName: "localhost"}, time.Minute, monitor.NoopMonitor{})
+	require.NoError(t, err)
+	resp, err := (&http.Client{Transport: overridden}).Get(server.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+}
+
+func TestTLSConfig_Validate(t *testing.T) {
+	assert.NoError(t, TLSConfig{}.Validate())
+	assert.Error(t, TLSConfig{CertFile: "cert.pem"}.Validate())
+	assert.Error(t, TLSConfig{MinVersion: "1.1"}.Validate())
+
+	_, err := NewTLSTransport(TLSConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")}, 0, monitor.NoopMonitor{})
+	assert.ErrorContains(t, err, "failed to read CA file")
+}
+
+// generateCert creates a self-signed certificate and returns the PEM encoded certificate and key
+func generateCert(t *testing.T, commonName string, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{usage},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{"localhost"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
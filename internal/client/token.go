@@ -0,0 +1,171 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package client
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenInfo describes the token currently used by the agent. It never contains the token itself.
+type TokenInfo struct {
+	IssuedAt   *time.Time `json:"issuedAt,omitempty"`
+	AcceptedAt time.Time  `json:"acceptedAt"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+}
+
+// Age returns how long the token has existed, measured from its issue time if known
+func (i TokenInfo) Age(now time.Time) time.Duration {
+	if i.IssuedAt != nil {
+		return now.Sub(*i.IssuedAt)
+	}
+	return now.Sub(i.AcceptedAt)
+}
+
+// ExpiresWithin returns true if the token has a known expiry that is less than d away
+func (i TokenInfo) ExpiresWithin(d time.Duration, now time.Time) bool {
+	return i.ExpiresAt != nil && i.ExpiresAt.Sub(now) < d
+}
+
+// TokenTracker records the lifecycle of the agent token and optionally persists accepted tokens to a secret file, so
+// a token received at runtime survives a restart
+type TokenTracker struct {
+	secretFile string
+
+	mu   sync.RWMutex
+	info TokenInfo
+}
+
+// NewTokenTracker creates a tracker for the token the agent started with. If secretFile is empty, tokens are not
+// persisted.
+func NewTokenTracker(token string, secretFile string, now time.Time) *TokenTracker {
+	t := &TokenTracker{secretFile: secretFile}
+	t.info = newTokenInfo(token, nil, now)
+	return t
+}
+
+// Info returns the current token information
+func (t *TokenTracker) Info() TokenInfo {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.info
+}
+
+// Accept records a new token. An explicit expiry takes precedence over one read from the token.
+func (t *TokenTracker) Accept(token string, expiresAt *time.Time, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.info = newTokenInfo(token, expiresAt, now)
+}
+
+// Persist writes the token to the secret file and returns the previous file content, which can be passed to Restore.
+// It does nothing if no secret file is configured.
+func (t *TokenTracker) Persist(token string) (previous []byte, err error) {
+	if t.secretFile == "" {
+		return nil, nil
+	}
+	previous, err = os.ReadFile(t.secretFile)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read secret file: %w", err)
+	}
+	if err := writeSecretFile(t.secretFile, []byte(token)); err != nil {
+		return nil, err
+	}
+	return previous, nil
+}
+
+// Restore puts back the secret file content returned by Persist
+func (t *TokenTracker) Restore(previous []byte) error {
+	if t.secretFile == "" {
+		return nil
+	}
+	if previous == nil {
+		return os.Remove(t.secretFile)
+	}
+	return writeSecretFile(t.secretFile, previous)
+}
+
+// ReadSecretFile returns the token persisted in the secret file, or an empty string if there is none
+func ReadSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// writeSecretFile replaces the file atomically, readable only by the owner
+func writeSecretFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write secret file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err == nil {
+		_, err = tmp.Write(data)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write secret file: %w", err)
+	}
+	return nil
+}
+
+// newTokenInfo reads the issue and expiry times from the token if it is a JWT. The claims are only inspected, not
+// verified; Fulcrum Core remains responsible for accepting the token.
+func newTokenInfo(token string, expiresAt *time.Time, now time.Time) TokenInfo {
+	info := TokenInfo{AcceptedAt: now, ExpiresAt: expiresAt}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return info
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return info
+	}
+	var claims struct {
+		IssuedAt  *int64 `json:"iat"`
+		ExpiresAt *int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return info
+	}
+	if claims.IssuedAt != nil {
+		issuedAt := time.Unix(*claims.IssuedAt, 0)
+		info.IssuedAt = &issuedAt
+	}
+	if info.ExpiresAt == nil && claims.ExpiresAt != nil {
+		exp := time.Unix(*claims.ExpiresAt, 0)
+		info.ExpiresAt = &exp
+	}
+	return info
+}
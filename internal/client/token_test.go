@@ -0,0 +1,66 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package client
+
+import (
+	"encoding/base64"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTokenTracker_JWTClaims(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"iat":1699996400,"exp":1700003600}`))
+	tracker := NewTokenTracker("header."+payload+".signature", "", now)
+
+	info := tracker.Info()
+	require.NotNil(t, info.IssuedAt)
+	require.NotNil(t, info.ExpiresAt)
+	assert.Equal(t, time.Hour, info.Age(now))
+	assert.True(t, info.ExpiresWithin(2*time.Hour, now))
+	assert.False(t, info.ExpiresWithin(30*time.Minute, now))
+
+	// opaque tokens have no known expiry and age from acceptance
+	tracker.Accept("opaque", nil, now)
+	info = tracker.Info()
+	assert.Nil(t, info.ExpiresAt)
+	assert.Equal(t, time.Minute, info.Age(now.Add(time.Minute)))
+	assert.False(t, info.ExpiresWithin(time.Hour, now))
+}
+
+func TestTokenTracker_PersistAndRestore(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "token")
+	tracker := NewTokenTracker("first", secretFile, time.Now())
+
+	previous, err := tracker.Persist("second")
+	require.NoError(t, err)
+	assert.Nil(t, previous)
+	token, err := ReadSecretFile(secretFile)
+	require.NoError(t, err)
+	assert.Equal(t, "second", token)
+
+	info, err := os.Stat(secretFile)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	previous, err = tracker.Persist("third")
+	require.NoError(t, err)
+	require.NoError(t, tracker.Restore(previous))
+	token, err = ReadSecretFile(secretFile)
+	require.NoError(t, err)
+	assert.Equal(t, "second", token)
+}
@@ -0,0 +1,66 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package client
+
+import (
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+)
+
+var tracer = otel.Tracer("github.com/metaform/cfm-fulcrum/internal/client")
+
+// tracingTransport records a client span for every request attempt and propagates the trace context to the upstream
+// service, e.g. in the W3C traceparent header. Spans and headers are only produced if tracing is configured.
+type tracingTransport struct {
+	next     http.RoundTripper
+	upstream string
+}
+
+// NewTracingTransport returns a transport that traces requests sent to the upstream service
+func NewTracingTransport(next http.RoundTripper, upstream string) http.RoundTripper {
+	return &tracingTransport{next: next, upstream: upstream}
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	endpoint := EndpointTemplate(req.URL.Path)
+	ctx, span := tracer.Start(req.Context(), fmt.Sprintf("%s %s", req.Method, endpoint),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("url.template", endpoint),
+			attribute.String("server.address", req.URL.Hostname()),
+			attribute.String("peer.service", t.upstream),
+		))
+	defer span.End()
+
+	// a round tripper must not modify the request it was given
+	req = req.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return resp, err
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+	return resp, nil
+}
@@ -0,0 +1,142 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package deployment
+
+import (
+	"fmt"
+	"github.com/metaform/cfm-fulcrum/internal/client"
+	"github.com/metaform/cfm-fulcrum/internal/job"
+	"github.com/metaform/connector-fabric-manager/common/system"
+	"strings"
+	"time"
+)
+
+// InventoryKey resolves the *Inventory of services provisioned by the agent
+const InventoryKey system.ServiceType = "deployment:Inventory"
+
+// TrackerKey resolves the *Tracker notified of the final status of deployments
+const TrackerKey system.ServiceType = "deployment:Tracker"
+
+const (
+	mappingsKey           = "deployment.mappings"
+	defaultTypeKey        = "deployment.default_type"
+	statusPathKey         = "deployment.status_path"
+	statusPollIntervalKey = "deployment.status_poll_interval"
+	timeoutKey            = "deployment.timeout"
+	actionTimeoutsKey     = "deployment.action_timeouts"
+	inventoryPathKey      = "deployment.inventory_path"
+
+	defaultDeploymentType     = "test.deployment"
+	defaultStatusPollInterval = 5 * time.Second
+	defaultTimeout            = 10 * time.Minute
+)
+
+// DeploymentServiceAssembly contributes job action handlers that provision services through the CFM Provision Manager
+type DeploymentServiceAssembly struct {
+	system.DefaultServiceAssembly
+}
+
+func (a *DeploymentServiceAssembly) Name() string {
+	return "Deployment Handlers"
+}
+
+func (d *DeploymentServiceAssembly) Provides() []system.ServiceType {
+	return []system.ServiceType{InventoryKey, TrackerKey}
+}
+
+func (d *DeploymentServiceAssembly) Requires() []system.ServiceType {
+	return []system.ServiceType{job.ActionRegistryKey, client.ApiClientKey}
+}
+
+func (a *DeploymentServiceAssembly) Init(context *system.InitContext) error {
+	registry := context.Registry.Resolve(job.ActionRegistryKey).(*job.ActionRegistry)
+	apiClient := context.Registry.Resolve(client.ApiClientKey).(client.ApiClient)
+
+	var mappings []Mapping
+	if err := context.Config.UnmarshalKey(mappingsKey, &mappings); err != nil {
+		return fmt.Errorf("invalid %s: %w", mappingsKey, err)
+	}
+	builder, err := NewManifestBuilder(mappings, context.GetConfigStrOrDefault(defaultTypeKey, defaultDeploymentType))
+	if err != nil {
+		return err
+	}
+
+	tracker, err := newTracker(context, apiClient)
+	if err != nil {
+		return err
+	}
+
+	inventory, err := NewInventory(context.GetConfigStrOrDefault(inventoryPathKey, ""))
+	if err != nil {
+		return err
+	}
+	context.Registry.Register(InventoryKey, inventory)
+	context.Registry.Register(TrackerKey, tracker)
+
+	handler := NewDeploymentHandler(apiClient, builder, tracker, inventory, context.LogMonitor)
+	for _, action := range client.JobActions {
+		registry.Register(action, handler)
+	}
+	return nil
+}
+
+func newTracker(context *system.InitContext, apiClient client.ApiClient) (*Tracker, error) {
+	pollInterval := defaultStatusPollInterval
+	if context.Config.IsSet(statusPollIntervalKey) {
+		pollInterval = context.Config.GetDuration(statusPollIntervalKey)
+	}
+	if pollInterval <= 0 {
+		return nil, fmt.Errorf("%s must be greater than 0, was: %s", statusPollIntervalKey, pollInterval)
+	}
+
+	timeout := defaultTimeout
+	if context.Config.IsSet(timeoutKey) {
+		timeout = context.Config.GetDuration(timeoutKey)
+	}
+	if timeout <= 0 {
+		return nil, fmt.Errorf("%s must be greater than 0, was: %s", timeoutKey, timeout)
+	}
+
+	// keys are matched case-insensitively since configuration keys are not case-sensitive
+	timeouts := make(map[client.JobAction]time.Duration)
+	for key, value := range context.Config.GetStringMapString(actionTimeoutsKey) {
+		action, found := findAction(key)
+		if !found {
+			return nil, fmt.Errorf("%s: unknown job action: %s", actionTimeoutsKey, key)
+		}
+		actionTimeout, err := time.ParseDuration(value)
+		if err != nil || actionTimeout <= 0 {
+			return nil, fmt.Errorf("%s: invalid timeout for %s: %s", actionTimeoutsKey, action, value)
+		}
+		timeouts[action] = actionTimeout
+	}
+
+	// the Provision Manager does not expose deployment status yet, so polling is only enabled if a status path is set
+	var source StatusSource
+	if statusPath := context.GetConfigStrOrDefault(statusPathKey, ""); statusPath != "" {
+		source = NewPManagerStatusSource(apiClient, statusPath)
+	} else {
+		context.LogMonitor.Infof("%s not set, deployments complete once their status is reported to "+
+			"POST /deployments/{id}/status of the management API", statusPathKey)
+	}
+	return NewTracker(source, pollInterval, timeout, timeouts, context.LogMonitor), nil
+}
+
+func findAction(name string) (client.JobAction, bool) {
+	for _, action := range client.JobActions {
+		if strings.EqualFold(string(action), name) {
+			return action, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,130 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package deployment
+
+import (
+	"context"
+	"github.com/metaform/cfm-fulcrum/internal/client"
+	"github.com/metaform/cfm-fulcrum/internal/job"
+	"github.com/metaform/connector-fabric-manager/common/monitor"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+// endpointsOutput is the orchestration output that contains the endpoints provisioned by a deployment
+const endpointsOutput = "endpoints"
+
+// DeploymentHandler is a job.ActionHandler that sends a deployment manifest to the Provision Manager
+type DeploymentHandler struct {
+	apiClient client.ApiClient
+	builder   *ManifestBuilder
+	tracker   *Tracker
+	inventory *Inventory
+	monitor   monitor.LogMonitor
+}
+
+// NewDeploymentHandler creates a new deployment handler. Completed deployments are recorded in the inventory.
+func NewDeploymentHandler(
+	apiClient client.ApiClient,
+	builder *ManifestBuilder,
+	tracker *Tracker,
+	inventory *Inventory,
+	monitor monitor.LogMonitor) *DeploymentHandler {
+	return &DeploymentHandler{
+		apiClient: apiClient,
+		builder:   builder,
+		tracker:   tracker,
+		inventory: inventory,
+		monitor:   monitor,
+	}
+}
+
+// Handle posts a deployment manifest for the job to the Provision Manager and waits for the deployment to complete.
+// The returned job.JobResponse links the Fulcrum service to CFM through its external ID.
+func (h *DeploymentHandler) Handle(ctx context.Context, fulcrumJob *client.Job) (any, error) {
+	startedAt := time.Now().UTC()
+	manifest, err := h.builder.Build(fulcrumJob)
+	if err != nil {
+		return nil, err
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(job.DeploymentIDAttribute.String(manifest.ID))
+	h.monitor.Debugf("Posting deployment %s of type %s for job %s", manifest.ID, manifest.DeploymentType, fulcrumJob.ID)
+	defer h.tracker.Expect(manifest.ID)()
+	body, err := h.apiClient.PostToPManager(ctx, "deployment", manifest)
+	if err != nil {
+		h.monitor.Severef("Error posting deployment for job %s: %v", fulcrumJob.ID, err)
+		return nil, err
+	}
+	accepted, err := ParseStatus(body)
+	if err != nil {
+		h.monitor.Debugf("Provision Manager returned no orchestration for deployment %s: %v", manifest.ID, err)
+	}
+
+	status, err := h.tracker.Await(ctx, manifest.ID, fulcrumJob.Action, accepted)
+	if err != nil {
+		return nil, err
+	}
+	h.monitor.Infof("Deployment %s for job %s completed", manifest.ID, fulcrumJob.ID)
+
+	externalID := externalID(fulcrumJob, manifest.ID)
+	h.record(fulcrumJob, *externalID, manifest.ID)
+
+	return &job.JobResponse{
+		Resources: job.JobResources{
+			TS:             time.Now().UTC(),
+			StartedAt:      &startedAt,
+			DeploymentID:   manifest.ID,
+			DeploymentType: manifest.DeploymentType,
+			Endpoints:      endpoints(status.Outputs),
+		},
+		ExternalID: externalID,
+	}, nil
+}
+
+// record updates the inventory after a deployment completed. Failing to record a service does not fail the job.
+func (h *DeploymentHandler) record(fulcrumJob *client.Job, externalID string, deploymentID string) {
+	var err error
+	if fulcrumJob.Action == client.JobActionServiceDelete {
+		err = h.inventory.Remove(externalID)
+	} else {
+		err = h.inventory.Put(Service{ExternalID: externalID, ServiceID: fulcrumJob.Service.ID, DeploymentID: deploymentID})
+	}
+	if err != nil {
+		h.monitor.Warnf("Unable to record service %s in inventory: %v", externalID, err)
+	}
+}
+
+// externalID returns the ID that identifies the service in CFM. The deployment that creates a service assigns its
+// ID; subsequent actions keep the ID already recorded by Fulcrum Core.
+func externalID(fulcrumJob *client.Job, deploymentID string) *string {
+	if fulcrumJob.Service.ExternalID != nil && *fulcrumJob.Service.ExternalID != "" {
+		return fulcrumJob.Service.ExternalID
+	}
+	return &deploymentID
+}
+
+// endpoints returns the endpoints reported by the deployment activities, ignoring values that are not strings
+func endpoints(outputs map[string]any) map[string]string {
+	values, ok := outputs[endpointsOutput].(map[string]any)
+	if !ok {
+		return nil
+	}
+	result := make(map[string]string, len(values))
+	for name, value := range values {
+		if endpoint, ok := value.(string); ok {
+			result[name] = endpoint
+		}
+	}
+	return result
+}
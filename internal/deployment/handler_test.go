@@ -0,0 +1,214 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package deployment
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/metaform/cfm-fulcrum/internal/client"
+	fjob "github.com/metaform/cfm-fulcrum/internal/job"
+	"github.com/metaform/connector-fabric-manager/common/monitor"
+	"github.com/metaform/connector-fabric-manager/pmanager/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDeploymentHandler_Handle(t *testing.T) {
+	job := newTestJob(client.JobActionServiceCreate)
+	deploymentID := DeploymentID(job)
+
+	var statusRequests atomic.Int32
+	pmanager := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/deployment":
+			var manifest api.DeploymentManifest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&manifest))
+			assert.Equal(t, deploymentID, manifest.ID)
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodGet && r.URL.Path == "/deployment/"+deploymentID:
+			state := api.OrchestrationStateRunning
+			if statusRequests.Add(1) >= 3 {
+				state = api.OrchestrationStateCompleted
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"id":             deploymentID,
+				"state":          state,
+				"ProcessingData": map[string]any{"endpoints": map[string]any{"dsp": "https://tenant.example.com/dsp", "invalid": 1}},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer pmanager.Close()
+
+	handler := newTestHandler(t, pmanager.URL, time.Second)
+
+	job.Service.ExternalID = nil
+	result, err := handler.Handle(context.Background(), job)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), statusRequests.Load())
+
+	response, ok := result.(*fjob.JobResponse)
+	require.True(t, ok)
+	require.NotNil(t, response.ExternalID)
+	assert.Equal(t, deploymentID, *response.ExternalID)
+	assert.Equal(t, deploymentID, response.Resources.DeploymentID)
+	assert.Equal(t, "test.deployment", response.Resources.DeploymentType)
+	assert.Equal(t, map[string]string{"dsp": "https://tenant.example.com/dsp"}, response.Resources.Endpoints)
+	assert.False(t, response.Resources.TS.Before(*response.Resources.StartedAt))
+	assert.Equal(t, []Service{{ExternalID: deploymentID, ServiceID: job.Service.ID, DeploymentID: deploymentID}}, handler.inventory.List())
+}
+
+func TestDeploymentHandler_HandleAccepted(t *testing.T) {
+	job := newTestJob(client.JobActionServiceCreate)
+	deploymentID := DeploymentID(job)
+
+	tracker := NewTracker(nil, time.Millisecond, time.Second, nil, monitor.NoopMonitor{})
+	pmanager := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/deployment" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		// the deployment may finish before the handler starts waiting for it
+		assert.True(t, tracker.Notify(deploymentID, &Status{State: api.OrchestrationStateCompleted}))
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id":             deploymentID,
+			"state":          api.OrchestrationStateInitialized,
+			"ProcessingData": map[string]any{"endpoints": map[string]any{"dsp": "https://tenant.example.com/dsp"}},
+		})
+	}))
+	defer pmanager.Close()
+
+	apiClient := *client.NewApiClient(pmanager.URL, "", "")
+	builder, err := NewManifestBuilder(nil, "test.deployment")
+	require.NoError(t, err)
+	inventory, err := NewInventory("")
+	require.NoError(t, err)
+	handler := NewDeploymentHandler(apiClient, builder, tracker, inventory, monitor.NoopMonitor{})
+
+	result, err := handler.Handle(context.Background(), job)
+	require.NoError(t, err)
+	response := result.(*fjob.JobResponse)
+	assert.Equal(t, deploymentID, response.Resources.DeploymentID)
+	assert.Equal(t, map[string]string{"dsp": "https://tenant.example.com/dsp"}, response.Resources.Endpoints)
+}
+
+func TestDeploymentHandler_HandleErrored(t *testing.T) {
+	pmanager := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"state": api.OrchestrationStateErrored})
+	}))
+	defer pmanager.Close()
+
+	handler := newTestHandler(t, pmanager.URL, time.Second)
+
+	_, err := handler.Handle(context.Background(), newTestJob(client.JobActionServiceCreate))
+	assert.ErrorContains(t, err, "failed")
+}
+
+func TestDeploymentHandler_KeepsExternalID(t *testing.T) {
+	pmanager := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"state": api.OrchestrationStateCompleted})
+	}))
+	defer pmanager.Close()
+
+	handler := newTestHandler(t, pmanager.URL, time.Second)
+
+	result, err := handler.Handle(context.Background(), newTestJob(client.JobActionServiceStop))
+	require.NoError(t, err)
+	assert.Equal(t, "external-1", *result.(*fjob.JobResponse).ExternalID)
+}
+
+func TestTracker_Timeout(t *testing.T) {
+	source := statusFunc(func(string) (*Status, error) {
+		return &Status{State: api.OrchestrationStateRunning}, nil
+	})
+	tracker := NewTracker(source, time.Millisecond, time.Hour, map[client.JobAction]time.Duration{
+		client.JobActionServiceDelete: 20 * time.Millisecond,
+	}, monitor.NoopMonitor{})
+
+	assert.Equal(t, time.Hour, tracker.Timeout(client.JobActionServiceCreate))
+
+	_, err := tracker.Await(context.Background(), "deployment-1", client.JobActionServiceDelete, nil)
+	assert.ErrorContains(t, err, "did not complete within 20ms")
+}
+
+func TestTracker_NotFound(t *testing.T) {
+	var calls atomic.Int32
+	source := statusFunc(func(string) (*Status, error) {
+		calls.Add(1)
+		return nil, &client.HTTPError{Method: http.MethodGet, URL: "http://pmanager/deployment/deployment-1", StatusCode: http.StatusNotFound}
+	})
+	tracker := NewTracker(source, time.Millisecond, time.Hour, nil, monitor.NoopMonitor{})
+
+	_, err := tracker.Await(context.Background(), "deployment-1", client.JobActionServiceCreate, nil)
+	assert.True(t, client.HasStatus(err, http.StatusNotFound))
+	assert.False(t, fjob.IsRetryable(err))
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestTracker_WithoutSource(t *testing.T) {
+	tracker := NewTracker(nil, time.Millisecond, time.Hour, map[client.JobAction]time.Duration{
+		client.JobActionServiceStop: 20 * time.Millisecond,
+	}, monitor.NoopMonitor{})
+	running := &Status{State: api.OrchestrationStateRunning, Outputs: map[string]any{"endpoints": "accepted"}}
+
+	release := tracker.Expect("deployment-1")
+	time.AfterFunc(10*time.Millisecond, func() {
+		tracker.Notify("deployment-1", &Status{State: api.OrchestrationStateRunning})
+		tracker.Notify("deployment-1", &Status{State: api.OrchestrationStateCompleted})
+	})
+	status, err := tracker.Await(context.Background(), "deployment-1", client.JobActionServiceCreate, running)
+	require.NoError(t, err)
+	assert.Equal(t, api.OrchestrationStateCompleted, status.State)
+	assert.Equal(t, running.Outputs, status.Outputs)
+	release()
+	assert.False(t, tracker.Notify("deployment-1", &Status{State: api.OrchestrationStateCompleted}))
+
+	defer tracker.Expect("deployment-2")()
+	_, err = tracker.Await(context.Background(), "deployment-2", client.JobActionServiceStop, running)
+	assert.ErrorContains(t, err, "did not complete within 20ms")
+
+	_, err = tracker.Await(context.Background(), "deployment-3", client.JobActionServiceCreate,
+		&Status{State: api.OrchestrationStateErrored})
+	assert.ErrorContains(t, err, "failed")
+}
+
+type statusFunc func(string) (*Status, error)
+
+func (f statusFunc) GetDeploymentStatus(_ context.Context, deploymentID string) (*Status, error) {
+	return f(deploymentID)
+}
+
+func newTestHandler(t *testing.T, pmanagerUrl string, timeout time.Duration) *DeploymentHandler {
+	apiClient := *client.NewApiClient(pmanagerUrl, "", "")
+	builder, err := NewManifestBuilder(nil, "test.deployment")
+	require.NoError(t, err)
+	tracker := NewTracker(NewPManagerStatusSource(apiClient, "deployment"), time.Millisecond, timeout, nil, monitor.NoopMonitor{})
+	inventory, err := NewInventory("")
+	require.NoError(t, err)
+	return NewDeploymentHandler(apiClient, builder, tracker, inventory, monitor.NoopMonitor{})
+}
@@ -0,0 +1,126 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package deployment
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Service is a Fulcrum service provisioned through CFM
+type Service struct {
+	ExternalID   string `json:"externalId"`
+	ServiceID    string `json:"serviceId"`
+	DeploymentID string `json:"deploymentId"`
+}
+
+// Inventory records the services the agent has provisioned, keyed by their external ID. Services are added when a
+// deployment completes and removed when they are deleted.
+//
+// If a path is set, the inventory is written to that file after every change and loaded from it when created, so it
+// survives a restart of the agent.
+type Inventory struct {
+	path string
+
+	mu       sync.RWMutex
+	services map[string]Service
+}
+
+// NewInventory creates an inventory, loading the file at path if it exists. An empty path keeps the inventory in
+// memory.
+func NewInventory(path string) (*Inventory, error) {
+	inventory := &Inventory{path: path, services: make(map[string]Service)}
+	if path == "" {
+		return inventory, nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return inventory, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read service inventory: %w", err)
+	}
+	var services []Service
+	if err := json.Unmarshal(data, &services); err != nil {
+		return nil, fmt.Errorf("failed to decode service inventory: %w", err)
+	}
+	for _, service := range services {
+		inventory.services[service.ExternalID] = service
+	}
+	return inventory, nil
+}
+
+// Put adds or replaces a service
+func (i *Inventory) Put(service Service) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if existing, found := i.services[service.ExternalID]; found && existing == service {
+		return nil
+	}
+	i.services[service.ExternalID] = service
+	return i.save()
+}
+
+// Remove removes the service with the given external ID. Removing a service that does not exist is not an error.
+func (i *Inventory) Remove(externalID string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if _, found := i.services[externalID]; !found {
+		return nil
+	}
+	delete(i.services, externalID)
+	return i.save()
+}
+
+// List returns all services ordered by external ID
+func (i *Inventory) List() []Service {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.sorted()
+}
+
+func (i *Inventory) sorted() []Service {
+	services := make([]Service, 0, len(i.services))
+	for _, service := range i.services {
+		services = append(services, service)
+	}
+	sort.Slice(services, func(a, b int) bool {
+		return services[a].ExternalID < services[b].ExternalID
+	})
+	return services
+}
+
+// save replaces the inventory file atomically
+func (i *Inventory) save() error {
+	if i.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(i.sorted())
+	if err != nil {
+		return fmt.Errorf("failed to encode service inventory: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(i.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create service inventory directory: %w", err)
+	}
+	tmp := i.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write service inventory: %w", err)
+	}
+	if err := os.Rename(tmp, i.path); err != nil {
+		return fmt.Errorf("failed to write service inventory: %w", err)
+	}
+	return nil
+}
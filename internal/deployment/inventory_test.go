@@ -0,0 +1,39 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package deployment
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+)
+
+func TestInventory_Persists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "inventory.json")
+	inventory, err := NewInventory(path)
+	require.NoError(t, err)
+
+	require.NoError(t, inventory.Put(Service{ExternalID: "b", ServiceID: "service-b", DeploymentID: "deployment-b"}))
+	require.NoError(t, inventory.Put(Service{ExternalID: "a", ServiceID: "service-a", DeploymentID: "deployment-a"}))
+	require.NoError(t, inventory.Put(Service{ExternalID: "c", ServiceID: "service-c", DeploymentID: "deployment-c"}))
+	require.NoError(t, inventory.Remove("c"))
+	require.NoError(t, inventory.Remove("unknown"))
+
+	reloaded, err := NewInventory(path)
+	require.NoError(t, err)
+	assert.Equal(t, []Service{
+		{ExternalID: "a", ServiceID: "service-a", DeploymentID: "deployment-a"},
+		{ExternalID: "b", ServiceID: "service-b", DeploymentID: "deployment-b"},
+	}, reloaded.List())
+}
@@ -0,0 +1,212 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package deployment
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/metaform/cfm-fulcrum/internal/client"
+	"github.com/metaform/connector-fabric-manager/pmanager/api"
+	"text/template"
+)
+
+// deploymentNamespace is the UUID namespace used to derive deployment IDs from jobs
+var deploymentNamespace = uuid.MustParse("6f1c7f0e-3b0a-4d8e-9f52-2a9c1d7e4b63")
+
+// Mapping selects the deployment type and payload for jobs of a Fulcrum service type.
+//
+// An empty Action matches all actions of the service type. Payload is an optional text/template that must render a
+// JSON object. Templates are executed against PayloadData; the json function encodes a value as JSON, for example:
+//
+//	{"tenantDid": {{ json .Properties.tenantDid }}, "service": {{ json .ServiceName }}}
+//
+// If no payload template is set, the payload produced by DefaultPayload is sent.
+type Mapping struct {
+	ServiceType    string `mapstructure:"serviceType"`
+	Action         string `mapstructure:"action"`
+	DeploymentType string `mapstructure:"deploymentType"`
+	Payload        string `mapstructure:"payload"`
+}
+
+// PayloadData is the data available to payload templates
+type PayloadData struct {
+	JobID             string
+	Action            client.JobAction
+	ServiceID         string
+	ServiceName       string
+	ServiceTypeID     string
+	ExternalID        string
+	CurrentProperties map[string]any
+	TargetProperties  map[string]any
+	// Properties are the target properties if set, otherwise the current properties
+	Properties map[string]any
+}
+
+type compiledMapping struct {
+	deploymentType string
+	payload        *template.Template
+}
+
+// ManifestBuilder creates Provision Manager deployment manifests from Fulcrum jobs
+type ManifestBuilder struct {
+	mappings    map[mappingKey]compiledMapping
+	defaultType string
+}
+
+type mappingKey struct {
+	serviceType string
+	action      client.JobAction
+}
+
+// NewManifestBuilder creates a builder from the given mappings. If defaultType is not empty, it is used for jobs
+// that do not match a mapping.
+func NewManifestBuilder(mappings []Mapping, defaultType string) (*ManifestBuilder, error) {
+	builder := &ManifestBuilder{
+		mappings:    make(map[mappingKey]compiledMapping, len(mappings)),
+		defaultType: defaultType,
+	}
+	for i, mapping := range mappings {
+		if mapping.ServiceType == "" {
+			return nil, fmt.Errorf("deployment mapping %d: serviceType is required", i)
+		}
+		if mapping.DeploymentType == "" {
+			return nil, fmt.Errorf("deployment mapping %d: deploymentType is required", i)
+		}
+		key := mappingKey{serviceType: mapping.ServiceType, action: client.JobAction(mapping.Action)}
+		if _, exists := builder.mappings[key]; exists {
+			return nil, fmt.Errorf("deployment mapping %d: duplicate mapping for service type %s and action '%s'", i, mapping.ServiceType, mapping.Action)
+		}
+		compiled := compiledMapping{deploymentType: mapping.DeploymentType}
+		if mapping.Payload != "" {
+			tmpl, err := template.New(mapping.DeploymentType).
+				Funcs(template.FuncMap{"json": toJSON}).
+				Option("missingkey=zero").
+				Parse(mapping.Payload)
+			if err != nil {
+				return nil, fmt.Errorf("deployment mapping %d: invalid payload template: %w", i, err)
+			}
+			compiled.payload = tmpl
+		}
+		builder.mappings[key] = compiled
+	}
+	return builder, nil
+}
+
+// Build creates the deployment manifest for the job
+func (b *ManifestBuilder) Build(job *client.Job) (*api.DeploymentManifest, error) {
+	mapping, err := b.resolve(job)
+	if err != nil {
+		return nil, err
+	}
+
+	data := newPayloadData(job)
+	payload := DefaultPayload(data)
+	if mapping.payload != nil {
+		if payload, err = renderPayload(mapping.payload, data); err != nil {
+			return nil, fmt.Errorf("failed to render payload for deployment type %s: %w", mapping.deploymentType, err)
+		}
+	}
+
+	return &api.DeploymentManifest{
+		ID:             DeploymentID(job),
+		DeploymentType: mapping.deploymentType,
+		Payload:        payload,
+	}, nil
+}
+
+// resolve returns the mapping for the job's service type and action, falling back to a mapping for all actions of the
+// service type and then to the default deployment type
+func (b *ManifestBuilder) resolve(job *client.Job) (compiledMapping, error) {
+	serviceType := job.Service.ServiceTypeID
+	if mapping, found := b.mappings[mappingKey{serviceType: serviceType, action: job.Action}]; found {
+		return mapping, nil
+	}
+	if mapping, found := b.mappings[mappingKey{serviceType: serviceType}]; found {
+		return mapping, nil
+	}
+	if b.defaultType != "" {
+		return compiledMapping{deploymentType: b.defaultType}, nil
+	}
+	return compiledMapping{}, fmt.Errorf("no deployment type mapped for service type '%s' and action %s", serviceType, job.Action)
+}
+
+// DeploymentID returns the ID of the deployment created for a job.
+//
+// The ID is a UUIDv5 derived from the job ID and action, so processing the same job again produces the same ID. The
+// Provision Manager de-duplicates deployments by ID, which makes re-posting a manifest after a crash or retry safe.
+func DeploymentID(job *client.Job) string {
+	return uuid.NewSHA1(deploymentNamespace, []byte(job.ID+"/"+string(job.Action))).String()
+}
+
+// DefaultPayload returns the payload sent when a mapping does not define a payload template
+func DefaultPayload(data PayloadData) map[string]any {
+	payload := map[string]any{
+		"jobId":       data.JobID,
+		"action":      string(data.Action),
+		"serviceId":   data.ServiceID,
+		"serviceName": data.ServiceName,
+		"properties":  data.Properties,
+	}
+	if data.ServiceTypeID != "" {
+		payload["serviceTypeId"] = data.ServiceTypeID
+	}
+	if data.ExternalID != "" {
+		payload["externalId"] = data.ExternalID
+	}
+	return payload
+}
+
+func newPayloadData(job *client.Job) PayloadData {
+	data := PayloadData{
+		JobID:             job.ID,
+		Action:            job.Action,
+		ServiceID:         job.Service.ID,
+		ServiceName:       job.Service.Name,
+		ServiceTypeID:     job.Service.ServiceTypeID,
+		CurrentProperties: job.Service.CurrentProperties,
+		TargetProperties:  job.Service.TargetProperties,
+		Properties:        job.Service.TargetProperties,
+	}
+	if job.Service.ExternalID != nil {
+		data.ExternalID = *job.Service.ExternalID
+	}
+	if data.Properties == nil {
+		data.Properties = job.Service.CurrentProperties
+	}
+	if data.Properties == nil {
+		data.Properties = make(map[string]any)
+	}
+	return data
+}
+
+func renderPayload(tmpl *template.Template, data PayloadData) (map[string]any, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	var payload map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &payload); err != nil {
+		return nil, fmt.Errorf("rendered payload is not a JSON object: %w", err)
+	}
+	return payload, nil
+}
+
+func toJSON(value any) (string, error) {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
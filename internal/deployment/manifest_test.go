@@ -0,0 +1,110 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package deployment
+
+import (
+	"github.com/metaform/cfm-fulcrum/internal/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+const tenantServiceType = "01940a2e-7b8f-7c4d-9e5a-3f2b1c8d9e0f"
+
+func TestManifestBuilder_Build(t *testing.T) {
+	builder, err := NewManifestBuilder([]Mapping{
+		{
+			ServiceType:    tenantServiceType,
+			DeploymentType: "tenant.deployment",
+			Payload:        `{"tenantDid": {{ json .Properties.tenantDid }}, "missing": {{ json .Properties.missing }}, "name": {{ json .ServiceName }}}`,
+		},
+		{
+			ServiceType:    tenantServiceType,
+			Action:         string(client.JobActionServiceDelete),
+			DeploymentType: "tenant.dispose",
+		},
+	}, "")
+	require.NoError(t, err)
+
+	job := newTestJob(client.JobActionServiceCreate)
+	manifest, err := builder.Build(job)
+	require.NoError(t, err)
+	assert.Equal(t, "tenant.deployment", manifest.DeploymentType)
+	assert.Equal(t, map[string]any{"tenantDid": "did:web:tenant.example.com", "missing": nil, "name": "tenant"}, manifest.Payload)
+
+	job = newTestJob(client.JobActionServiceDelete)
+	manifest, err = builder.Build(job)
+	require.NoError(t, err)
+	assert.Equal(t, "tenant.dispose", manifest.DeploymentType)
+	assert.Equal(t, "service-1", manifest.Payload["serviceId"])
+	assert.Equal(t, "external-1", manifest.Payload["externalId"])
+	assert.Equal(t, job.Service.TargetProperties, manifest.Payload["properties"])
+}
+
+func TestDeploymentID(t *testing.T) {
+	create := newTestJob(client.JobActionServiceCreate)
+	id := DeploymentID(create)
+
+	assert.Equal(t, id, DeploymentID(newTestJob(client.JobActionServiceCreate)))
+	assert.NotEqual(t, id, DeploymentID(newTestJob(client.JobActionServiceDelete)))
+
+	other := newTestJob(client.JobActionServiceCreate)
+	other.ID = "job-2"
+	assert.NotEqual(t, id, DeploymentID(other))
+
+	builder, err := NewManifestBuilder(nil, "test.deployment")
+	require.NoError(t, err)
+	manifest, err := builder.Build(create)
+	require.NoError(t, err)
+	assert.Equal(t, id, manifest.ID)
+}
+
+func TestManifestBuilder_Unmapped(t *testing.T) {
+	builder, err := NewManifestBuilder(nil, "")
+	require.NoError(t, err)
+
+	_, err = builder.Build(newTestJob(client.JobActionServiceCreate))
+	assert.ErrorContains(t, err, "no deployment type mapped")
+
+	builder, err = NewManifestBuilder(nil, "test.deployment")
+	require.NoError(t, err)
+
+	manifest, err := builder.Build(newTestJob(client.JobActionServiceCreate))
+	require.NoError(t, err)
+	assert.Equal(t, "test.deployment", manifest.DeploymentType)
+}
+
+func TestNewManifestBuilder_Invalid(t *testing.T) {
+	_, err := NewManifestBuilder([]Mapping{{ServiceType: tenantServiceType}}, "")
+	assert.ErrorContains(t, err, "deploymentType is required")
+
+	_, err = NewManifestBuilder([]Mapping{{ServiceType: tenantServiceType, DeploymentType: "a", Payload: "{{ .Foo"}}, "")
+	assert.ErrorContains(t, err, "invalid payload template")
+
+	_, err = NewManifestBuilder([]Mapping{
+		{ServiceType: tenantServiceType, DeploymentType: "a"},
+		{ServiceType: tenantServiceType, DeploymentType: "b"},
+	}, "")
+	assert.ErrorContains(t, err, "duplicate mapping")
+}
+
+func newTestJob(action client.JobAction) *client.Job {
+	externalID := "external-1"
+	job := &client.Job{ID: "job-1", Action: action}
+	job.Service.ID = "service-1"
+	job.Service.Name = "tenant"
+	job.Service.ServiceTypeID = tenantServiceType
+	job.Service.ExternalID = &externalID
+	job.Service.TargetProperties = map[string]any{"tenantDid": "did:web:tenant.example.com"}
+	return job
+}
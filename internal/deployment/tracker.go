@@ -0,0 +1,228 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package deployment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/metaform/cfm-fulcrum/internal/client"
+	"github.com/metaform/cfm-fulcrum/internal/job"
+	"github.com/metaform/connector-fabric-manager/common/monitor"
+	"github.com/metaform/connector-fabric-manager/pmanager/api"
+	"sync"
+	"time"
+)
+
+// Status is the state of a Provision Manager deployment and the data produced by its activities
+type Status struct {
+	State   api.OrchestrationState
+	Outputs map[string]any
+}
+
+// ParseStatus decodes the orchestration returned by the Provision Manager for a deployment
+func ParseStatus(body []byte) (*Status, error) {
+	var orchestration struct {
+		State          api.OrchestrationState `json:"state"`
+		ProcessingData map[string]any
+	}
+	if err := json.Unmarshal(body, &orchestration); err != nil {
+		return nil, fmt.Errorf("failed to decode deployment status: %w", err)
+	}
+	return &Status{State: orchestration.State, Outputs: orchestration.ProcessingData}, nil
+}
+
+// StatusSource returns the current status of a Provision Manager deployment
+type StatusSource interface {
+	GetDeploymentStatus(ctx context.Context, deploymentID string) (*Status, error)
+}
+
+// PManagerStatusSource reads deployment state from a Provision Manager status endpoint that returns the orchestration
+// of a deployment at {statusPath}/{deploymentID}. Provision Manager releases without such an endpoint cannot be
+// polled; see Tracker.
+type PManagerStatusSource struct {
+	apiClient  client.ApiClient
+	statusPath string
+}
+
+func NewPManagerStatusSource(apiClient client.ApiClient, statusPath string) *PManagerStatusSource {
+	return &PManagerStatusSource{apiClient: apiClient, statusPath: statusPath}
+}
+
+// GetDeploymentStatus returns the status of the orchestration executing the deployment
+func (s *PManagerStatusSource) GetDeploymentStatus(ctx context.Context, deploymentID string) (*Status, error) {
+	body, err := s.apiClient.GetFromPManager(ctx, fmt.Sprintf("%s/%s", s.statusPath, deploymentID))
+	if err != nil {
+		return nil, err
+	}
+	return ParseStatus(body)
+}
+
+// Tracker waits for Provision Manager deployments to finish.
+//
+// Deployments execute asynchronously after the manifest is accepted, so a job must not be reported as complete to
+// Fulcrum Core until the deployment orchestration has completed. Completion is observed by polling the status source,
+// if there is one, and through Notify, which is called when the final status of a deployment is reported to the
+// management API. A deployment that does not finish within the timeout of its job action fails.
+type Tracker struct {
+	source         StatusSource
+	pollInterval   time.Duration
+	defaultTimeout time.Duration
+	timeouts       map[client.JobAction]time.Duration
+	monitor        monitor.LogMonitor
+
+	mu      sync.Mutex
+	pending map[string]chan *Status
+}
+
+// NewTracker creates a tracker that polls the source at the given interval. A nil source disables polling, so that
+// completion must be reported through Notify. Timeouts override the default timeout for specific job actions.
+func NewTracker(
+	source StatusSource,
+	pollInterval time.Duration,
+	defaultTimeout time.Duration,
+	timeouts map[client.JobAction]time.Duration,
+	monitor monitor.LogMonitor) *Tracker {
+	return &Tracker{
+		source:         source,
+		pollInterval:   pollInterval,
+		defaultTimeout: defaultTimeout,
+		timeouts:       timeouts,
+		monitor:        monitor,
+		pending:        make(map[string]chan *Status),
+	}
+}
+
+// Expect registers a deployment whose final status may be reported through Notify. It is called before the deployment
+// is posted, so that a status reported before Await starts waiting is not lost. The returned function ends the
+// registration.
+func (t *Tracker) Expect(deploymentID string) func() {
+	notified := make(chan *Status, 1)
+	t.mu.Lock()
+	t.pending[deploymentID] = notified
+	t.mu.Unlock()
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if t.pending[deploymentID] == notified {
+			delete(t.pending, deploymentID)
+		}
+	}
+}
+
+// Notify reports the status of a deployment, resolving Await once the status is final. It returns false if the
+// deployment is not expected. Statuses that are not final are accepted and ignored.
+func (t *Tracker) Notify(deploymentID string, status *Status) bool {
+	t.mu.Lock()
+	notified, found := t.pending[deploymentID]
+	t.mu.Unlock()
+	if !found {
+		return false
+	}
+	if status.State == api.OrchestrationStateCompleted || status.State == api.OrchestrationStateErrored {
+		select {
+		case notified <- status:
+		default:
+			// a final status was already reported
+		}
+	}
+	return true
+}
+
+// Timeout returns how long the tracker waits for a deployment created for the given action
+func (t *Tracker) Timeout(action client.JobAction) time.Duration {
+	if timeout, found := t.timeouts[action]; found {
+		return timeout
+	}
+	return t.defaultTimeout
+}
+
+// Await blocks until the deployment completes and returns its final status. The accepted status is the orchestration
+// returned when the deployment was posted; it is only final if its orchestration already completed or failed. Outputs
+// of the accepted status are returned if the final status carries none.
+//
+// An error is returned if the deployment fails, the action timeout elapses, or the context is cancelled. Errors
+// reading the deployment state are treated as transient and polling continues until the timeout, except for responses
+// that cannot succeed when repeated, such as 404 from a Provision Manager without a status endpoint.
+func (t *Tracker) Await(ctx context.Context, deploymentID string, action client.JobAction, accepted *Status) (*Status, error) {
+	if accepted != nil {
+		switch accepted.State {
+		case api.OrchestrationStateCompleted:
+			return accepted, nil
+		case api.OrchestrationStateErrored:
+			return nil, fmt.Errorf("deployment %s failed", deploymentID)
+		}
+	}
+
+	t.mu.Lock()
+	notified := t.pending[deploymentID] // nil if the deployment is not expected, which never receives
+	t.mu.Unlock()
+
+	timeout := t.Timeout(action)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var poll <-chan time.Time
+	if t.source != nil {
+		ticker := time.NewTicker(t.pollInterval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+
+	var lastErr error
+	for {
+		if t.source != nil {
+			status, err := t.source.GetDeploymentStatus(ctx, deploymentID)
+			if err != nil {
+				var httpErr *client.HTTPError
+				if errors.As(err, &httpErr) && !httpErr.Retryable {
+					return nil, job.NewPermanentError(fmt.Errorf("unable to read status of deployment %s: %w", deploymentID, err))
+				}
+				lastErr = err
+				t.monitor.Debugf("Unable to read status of deployment %s: %v", deploymentID, err)
+			} else {
+				lastErr = nil
+				switch status.State {
+				case api.OrchestrationStateCompleted, api.OrchestrationStateErrored:
+					return finalStatus(deploymentID, status, accepted)
+				}
+			}
+		}
+
+		select {
+		case status := <-notified:
+			return finalStatus(deploymentID, status, accepted)
+		case <-poll:
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				if lastErr != nil {
+					return nil, fmt.Errorf("deployment %s did not complete within %s: %v", deploymentID, timeout, lastErr)
+				}
+				return nil, fmt.Errorf("deployment %s did not complete within %s", deploymentID, timeout)
+			}
+			return nil, fmt.Errorf("stopped waiting for deployment %s: %w", deploymentID, ctx.Err())
+		}
+	}
+}
+
+// finalStatus returns the completed status, or an error if the deployment failed
+func finalStatus(deploymentID string, status *Status, accepted *Status) (*Status, error) {
+	if status.State == api.OrchestrationStateErrored {
+		return nil, fmt.Errorf("deployment %s failed", deploymentID)
+	}
+	if status.Outputs == nil && accepted != nil {
+		return &Status{State: status.State, Outputs: accepted.Outputs}, nil
+	}
+	return status, nil
+}
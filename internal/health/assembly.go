@@ -0,0 +1,144 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package health
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/metaform/cfm-fulcrum/internal/client"
+	"github.com/metaform/cfm-fulcrum/internal/job"
+	"github.com/metaform/cfm-fulcrum/internal/sysconfig"
+	"github.com/metaform/connector-fabric-manager/assembly/routing"
+	"github.com/metaform/connector-fabric-manager/common/system"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	livenessPathKey      = "health.liveness_path"
+	readinessPathKey     = "health.readiness_path"
+	timeoutKey           = "health.timeout"
+	cacheTTLKey          = "health.cache_ttl"
+	pmanagerCheckPathKey = "health.pmanager_check_path"
+	tmanagerCheckPathKey = "health.tmanager_check_path"
+
+	defaultLivenessPath  = "/healthz"
+	defaultReadinessPath = "/readyz"
+	defaultTimeout       = 3 * time.Second
+	defaultCacheTTL      = 5 * time.Second
+	defaultCheckPath     = "health"
+)
+
+// HealthServiceAssembly serves the liveness and readiness endpoints used by orchestrators such as Kubernetes. The
+// endpoints are not authenticated. The agent is live while the job poll loop makes progress, and ready while Fulcrum
+// Core accepts the agent token and the Provision and Tenant Managers are reachable. An empty check path disables the
+// check of that manager.
+type HealthServiceAssembly struct {
+	system.DefaultServiceAssembly
+}
+
+func (a *HealthServiceAssembly) Name() string {
+	return "Health"
+}
+
+func (a *HealthServiceAssembly) Requires() []system.ServiceType {
+	return []system.ServiceType{
+		routing.RouterKey,
+		client.FulcrumClientKey,
+		client.ApiClientKey,
+		client.TManagerHttpClientKey,
+		job.LivenessKey,
+	}
+}
+
+func (a *HealthServiceAssembly) Init(context *system.InitContext) error {
+	router := context.Registry.Resolve(routing.RouterKey).(chi.Router)
+	fulcrumClient := context.Registry.Resolve(client.FulcrumClientKey).(client.FulcrumClient)
+	apiClient := context.Registry.Resolve(client.ApiClientKey).(client.ApiClient)
+	tmanagerClient := context.Registry.Resolve(client.TManagerHttpClientKey).(*http.Client)
+	liveness := context.Registry.Resolve(job.LivenessKey).(*job.Liveness)
+
+	timeout := defaultTimeout
+	if context.Config.IsSet(timeoutKey) {
+		timeout = context.Config.GetDuration(timeoutKey)
+	}
+	if timeout <= 0 {
+		return fmt.Errorf("%s must be greater than 0, was: %s", timeoutKey, timeout)
+	}
+	ttl := defaultCacheTTL
+	if context.Config.IsSet(cacheTTLKey) {
+		ttl = context.Config.GetDuration(cacheTTLKey)
+	}
+
+	live := map[string]Check{
+		"pollLoop": pollLoopCheck(liveness),
+	}
+	ready := map[string]Check{
+		"fulcrum": fulcrumCheck(fulcrumClient),
+	}
+	if path := context.GetConfigStrOrDefault(pmanagerCheckPathKey, defaultCheckPath); path != "" {
+		ready["pmanager"] = client.PManagerCheck(apiClient, path)
+	}
+	if path := context.GetConfigStrOrDefault(tmanagerCheckPathKey, defaultCheckPath); path != "" {
+		tmanagerUrl := context.Config.GetString(sysconfig.TManagerUrlKey)
+		ready["tmanager"] = httpCheck(tmanagerClient, joinUrl(tmanagerUrl, path))
+	}
+
+	if path := context.GetConfigStrOrDefault(livenessPathKey, defaultLivenessPath); path != "" {
+		router.Method(http.MethodGet, path, NewChecker(live, timeout, ttl))
+	}
+	if path := context.GetConfigStrOrDefault(readinessPathKey, defaultReadinessPath); path != "" {
+		router.Method(http.MethodGet, path, NewChecker(ready, timeout, ttl))
+	}
+	return nil
+}
+
+// pollLoopCheck fails if the job poll loop has stopped ticking or a poll cycle is stuck
+func pollLoopCheck(liveness *job.Liveness) Check {
+	return func(context.Context) error {
+		return liveness.Check(time.Now())
+	}
+}
+
+// fulcrumCheck fails if Fulcrum Core is unreachable or does not accept the agent token
+func fulcrumCheck(fulcrumClient client.FulcrumClient) Check {
+	return func(ctx context.Context) error {
+		_, err := fulcrumClient.GetAgentInfo(ctx)
+		return err
+	}
+}
+
+// httpCheck fails if a GET request to the URL does not return a successful status
+func httpCheck(httpClient *http.Client, url string) Check {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("GET %s returned status %d", url, resp.StatusCode)
+		}
+		return nil
+	}
+}
+
+func joinUrl(baseUrl string, path string) string {
+	return strings.TrimSuffix(baseUrl, "/") + "/" + strings.TrimPrefix(path, "/")
+}
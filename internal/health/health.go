@@ -0,0 +1,132 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Status is the state of a health check or of a set of checks
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+// Check returns an error if the checked component is unhealthy. Checks must return once the context is done.
+type Check func(ctx context.Context) error
+
+// CheckResult is the outcome of a single check
+type CheckResult struct {
+	Status     Status    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"durationMs"`
+	CheckedAt  time.Time `json:"checkedAt"`
+}
+
+// Report is the outcome of a set of checks. It is up if all checks are up.
+type Report struct {
+	Status Status                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// Checker runs a set of checks concurrently and caches the report, so that frequent probes do not put load on the
+// checked services. A check that does not complete within the timeout is reported as down.
+type Checker struct {
+	checks  map[string]Check
+	timeout time.Duration
+	ttl     time.Duration
+	now     func() time.Time
+
+	mu      sync.Mutex
+	report  Report
+	expires time.Time
+}
+
+// NewChecker creates a checker for the named checks. Reports are reused for the ttl; zero disables caching.
+func NewChecker(checks map[string]Check, timeout time.Duration, ttl time.Duration) *Checker {
+	return &Checker{checks: checks, timeout: timeout, ttl: ttl, now: time.Now}
+}
+
+// Run returns the cached report, or runs the checks if it has expired. Concurrent callers wait for a single run.
+func (c *Checker) Run(ctx context.Context) Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.report.Status != "" && c.now().Before(c.expires) {
+		return c.report
+	}
+	// the report is shared with other callers, so it must not depend on the cancellation of this caller
+	c.report = c.run(context.WithoutCancel(ctx))
+	c.expires = c.now().Add(c.ttl)
+	return c.report
+}
+
+func (c *Checker) run(ctx context.Context) Report {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	type named struct {
+		name   string
+		result CheckResult
+	}
+	results := make(chan named, len(c.checks))
+	for name, check := range c.checks {
+		go func() {
+			results <- named{name: name, result: c.runCheck(ctx, check)}
+		}()
+	}
+
+	report := Report{Status: StatusUp, Checks: make(map[string]CheckResult, len(c.checks))}
+	for range c.checks {
+		r := <-results
+		report.Checks[r.name] = r.result
+		if r.result.Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+// runCheck runs a check bound to the context of the run
+func (c *Checker) runCheck(ctx context.Context, check Check) CheckResult {
+	start := c.now()
+	err := check(ctx)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("check did not complete within %s: %w", c.timeout, err)
+	}
+
+	result := CheckResult{Status: StatusUp, DurationMs: c.now().Sub(start).Milliseconds(), CheckedAt: start}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// ServeHTTP writes the report as JSON with status 200 if all checks are up, otherwise 503
+func (c *Checker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	report := c.Run(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.Status != StatusUp {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,106 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestChecker_ServeHTTP(t *testing.T) {
+	checker := NewChecker(map[string]Check{
+		"up":   func(context.Context) error { return nil },
+		"down": func(context.Context) error { return errors.New("unreachable") },
+	}, time.Second, 0)
+
+	rec := httptest.NewRecorder()
+	checker.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var report Report
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&report))
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Equal(t, StatusUp, report.Checks["up"].Status)
+	assert.Empty(t, report.Checks["up"].Error)
+	assert.Equal(t, StatusDown, report.Checks["down"].Status)
+	assert.Equal(t, "unreachable", report.Checks["down"].Error)
+}
+
+func TestChecker_AllUp(t *testing.T) {
+	checker := NewChecker(map[string]Check{
+		"up": func(context.Context) error { return nil },
+	}, time.Second, 0)
+
+	rec := httptest.NewRecorder()
+	checker.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var report Report
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&report))
+	assert.Equal(t, StatusUp, report.Status)
+}
+
+func TestChecker_Caching(t *testing.T) {
+	var calls atomic.Int32
+	checker := NewChecker(map[string]Check{
+		"counted": func(context.Context) error {
+			calls.Add(1)
+			return nil
+		},
+	}, time.Second, time.Minute)
+	now := time.Now()
+	checker.now = func() time.Time { return now }
+
+	checker.Run(context.Background())
+	checker.Run(context.Background())
+	assert.Equal(t, int32(1), calls.Load())
+
+	now = now.Add(time.Minute)
+	checker.Run(context.Background())
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestChecker_Timeout(t *testing.T) {
+	checker := NewChecker(map[string]Check{
+		"stuck": func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}, 20*time.Millisecond, 0)
+
+	report := checker.Run(context.Background())
+
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Contains(t, report.Checks["stuck"].Error, "did not complete")
+}
+
+func TestChecker_CancelledCaller(t *testing.T) {
+	checker := NewChecker(map[string]Check{
+		"context": func(ctx context.Context) error { return ctx.Err() },
+	}, time.Second, time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report := checker.Run(ctx)
+
+	assert.Equal(t, StatusUp, report.Status)
+}
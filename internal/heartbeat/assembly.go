@@ -0,0 +1,137 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package heartbeat
+
+import (
+	"context"
+	"github.com/metaform/cfm-fulcrum/internal/client"
+	"github.com/metaform/connector-fabric-manager/common/monitor"
+	"github.com/metaform/connector-fabric-manager/common/system"
+	"time"
+)
+
+const (
+	intervalKey           = "heartbeat.interval"
+	pmanagerCheckPathKey  = "heartbeat.pmanager_check_path"
+	checkTimeoutKey       = "heartbeat.check_timeout"
+	connectedStatusKey    = "heartbeat.status.connected"
+	disconnectedStatusKey = "heartbeat.status.disconnected"
+	errorStatusKey        = "heartbeat.status.error"
+
+	defaultInterval         = 30 * time.Second
+	defaultPManagerCheck    = "health"
+	defaultCheckTimeout     = 10 * time.Second
+	disconnectReportTimeout = 5 * time.Second
+)
+
+// HeartbeatServiceAssembly reports the agent status to Fulcrum Core while the agent is running
+type HeartbeatServiceAssembly struct {
+	system.DefaultServiceAssembly
+	heartbeat *Heartbeat
+	interval  time.Duration
+	monitor   monitor.LogMonitor
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+func (a *HeartbeatServiceAssembly) Name() string {
+	return "Heartbeat"
+}
+
+func (a *HeartbeatServiceAssembly) Requires() []system.ServiceType {
+	return []system.ServiceType{client.FulcrumClientKey, client.ApiClientKey}
+}
+
+func (a *HeartbeatServiceAssembly) Init(context *system.InitContext) error {
+	fulcrumClient := context.Registry.Resolve(client.FulcrumClientKey).(client.FulcrumClient)
+	apiClient := context.Registry.Resolve(client.ApiClientKey).(client.ApiClient)
+
+	a.interval = defaultInterval
+	if context.Config.IsSet(intervalKey) {
+		a.interval = context.Config.GetDuration(intervalKey)
+	}
+
+	defaults := DefaultStatuses()
+	statuses := Statuses{
+		Connected:    context.GetConfigStrOrDefault(connectedStatusKey, defaults.Connected),
+		Disconnected: context.GetConfigStrOrDefault(disconnectedStatusKey, defaults.Disconnected),
+		Error:        context.GetConfigStrOrDefault(errorStatusKey, defaults.Error),
+	}
+
+	checkTimeout := defaultCheckTimeout
+	if context.Config.IsSet(checkTimeoutKey) {
+		checkTimeout = context.Config.GetDuration(checkTimeoutKey)
+	}
+
+	checks := make(map[string]Check)
+	if path := context.GetConfigStrOrDefault(pmanagerCheckPathKey, defaultPManagerCheck); path != "" {
+		checks["pmanager"] = withTimeout(client.PManagerCheck(apiClient, path), checkTimeout)
+	}
+
+	a.monitor = context.LogMonitor
+	a.heartbeat = NewHeartbeat(fulcrumClient, checks, statuses, context.LogMonitor)
+	return nil
+}
+
+func (a *HeartbeatServiceAssembly) Start(_ *system.StartContext) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
+	a.done = make(chan struct{})
+
+	go func() {
+		defer close(a.done)
+		if err := a.heartbeat.Connect(ctx); err != nil {
+			a.monitor.Warnf("Unable to report agent status: %v", err)
+		}
+		if a.interval <= 0 {
+			return
+		}
+		ticker := time.NewTicker(a.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := a.heartbeat.Beat(ctx); err != nil && ctx.Err() == nil {
+					a.monitor.Warnf("Unable to report agent status: %v", err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (a *HeartbeatServiceAssembly) Finalize() error {
+	if a.cancel == nil {
+		return nil
+	}
+	a.cancel()
+	<-a.done
+
+	ctx, cancel := context.WithTimeout(context.Background(), disconnectReportTimeout)
+	defer cancel()
+	if err := a.heartbeat.Disconnect(ctx); err != nil {
+		a.monitor.Warnf("Unable to report agent status: %v", err)
+	}
+	return nil
+}
+
+// withTimeout bounds a check, so a hung upstream service is reported as failing instead of stalling the heartbeat
+func withTimeout(check Check, timeout time.Duration) Check {
+	return func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return check(ctx)
+	}
+}
@@ -0,0 +1,127 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package heartbeat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/metaform/cfm-fulcrum/internal/client"
+	"github.com/metaform/connector-fabric-manager/common/monitor"
+	"sync"
+)
+
+// Statuses maps agent states to the status values reported to Fulcrum Core
+type Statuses struct {
+	Connected    string
+	Disconnected string
+	Error        string
+}
+
+// DefaultStatuses returns the status values defined by Fulcrum Core
+func DefaultStatuses() Statuses {
+	return Statuses{
+		Connected:    "Connected",
+		Disconnected: "Disconnected",
+		Error:        "Error",
+	}
+}
+
+// Check verifies that an upstream service the agent depends on is available
+type Check func(ctx context.Context) error
+
+// Heartbeat reports the agent status to Fulcrum Core. The agent is reported as connected while all checks pass and
+// as in error otherwise.
+type Heartbeat struct {
+	fulcrumClient client.FulcrumClient
+	checks        map[string]Check
+	statuses      Statuses
+	monitor       monitor.LogMonitor
+
+	mu       sync.Mutex
+	reported string
+	failing  string
+}
+
+// NewHeartbeat creates a heartbeat running the given checks, keyed by a name used in log messages
+func NewHeartbeat(fulcrumClient client.FulcrumClient, checks map[string]Check, statuses Statuses, monitor monitor.LogMonitor) *Heartbeat {
+	return &Heartbeat{
+		fulcrumClient: fulcrumClient,
+		checks:        checks,
+		statuses:      statuses,
+		monitor:       monitor,
+	}
+}
+
+// Connect reports the agent as connected without running the checks
+func (h *Heartbeat) Connect(ctx context.Context) error {
+	return h.report(ctx, h.statuses.Connected)
+}
+
+// Beat runs the checks and reports the resulting status
+func (h *Heartbeat) Beat(ctx context.Context) error {
+	var errs []error
+	for name, check := range h.checks {
+		if err := check(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	err := errors.Join(errs...)
+
+	h.mu.Lock()
+	previous := h.failing
+	h.failing = ""
+	if err != nil {
+		h.failing = err.Error()
+	}
+	h.mu.Unlock()
+
+	status := h.statuses.Connected
+	switch {
+	case err != nil:
+		status = h.statuses.Error
+		if err.Error() != previous {
+			h.monitor.Warnf("Upstream check failed, reporting agent status %s: %v", status, err)
+		}
+	case previous != "":
+		h.monitor.Infof("Upstream checks recovered")
+	}
+	return h.report(ctx, status)
+}
+
+// Disconnect reports the agent as disconnected
+func (h *Heartbeat) Disconnect(ctx context.Context) error {
+	return h.report(ctx, h.statuses.Disconnected)
+}
+
+// Reported returns the last status successfully reported to Fulcrum Core
+func (h *Heartbeat) Reported() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.reported
+}
+
+func (h *Heartbeat) report(ctx context.Context, status string) error {
+	if err := h.fulcrumClient.UpdateAgentStatus(ctx, status); err != nil {
+		return fmt.Errorf("failed to report agent status %s: %w", status, err)
+	}
+
+	h.mu.Lock()
+	changed := h.reported != status
+	h.reported = status
+	h.mu.Unlock()
+	if changed {
+		h.monitor.Infof("Reported agent status %s", status)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package heartbeat
+
+import (
+	"context"
+	"errors"
+	"github.com/metaform/cfm-fulcrum/internal/client/mocks"
+	"github.com/metaform/connector-fabric-manager/common/monitor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestHeartbeat_Lifecycle(t *testing.T) {
+	fulcrumClient := mocks.NewFulcrumClient(t)
+	var checkErr error
+	checks := map[string]Check{"pmanager": func(context.Context) error { return checkErr }}
+	heartbeat := NewHeartbeat(fulcrumClient, checks, DefaultStatuses(), monitor.NoopMonitor{})
+
+	fulcrumClient.EXPECT().UpdateAgentStatus(mock.Anything, "Connected").Return(nil).Twice()
+	require.NoError(t, heartbeat.Connect(context.Background()))
+	require.NoError(t, heartbeat.Beat(context.Background()))
+	assert.Equal(t, "Connected", heartbeat.Reported())
+
+	checkErr = errors.New("unreachable")
+	fulcrumClient.EXPECT().UpdateAgentStatus(mock.Anything, "Error").Return(nil).Once()
+	require.NoError(t, heartbeat.Beat(context.Background()))
+	assert.Equal(t, "Error", heartbeat.Reported())
+
+	fulcrumClient.EXPECT().UpdateAgentStatus(mock.Anything, "Disconnected").Return(nil).Once()
+	require.NoError(t, heartbeat.Disconnect(context.Background()))
+	assert.Equal(t, "Disconnected", heartbeat.Reported())
+}
+
+func TestHeartbeat_StatusMapping(t *testing.T) {
+	fulcrumClient := mocks.NewFulcrumClient(t)
+	statuses := Statuses{Connected: "Online", Disconnected: "Offline", Error: "Degraded"}
+	checks := map[string]Check{"pmanager": func(context.Context) error { return errors.New("down") }}
+	heartbeat := NewHeartbeat(fulcrumClient, checks, statuses, monitor.NoopMonitor{})
+
+	fulcrumClient.EXPECT().UpdateAgentStatus(mock.Anything, "Degraded").Return(nil).Once()
+	require.NoError(t, heartbeat.Beat(context.Background()))
+}
+
+func TestHeartbeat_ReportFailure(t *testing.T) {
+	fulcrumClient := mocks.NewFulcrumClient(t)
+	heartbeat := NewHeartbeat(fulcrumClient, nil, DefaultStatuses(), monitor.NoopMonitor{})
+
+	fulcrumClient.EXPECT().UpdateAgentStatus(mock.Anything, "Connected").Return(errors.New("unavailable")).Once()
+	assert.ErrorContains(t, heartbeat.Connect(context.Background()), "failed to report agent status Connected")
+	assert.Empty(t, heartbeat.Reported())
+}
+
+func TestHeartbeat_HungCheck(t *testing.T) {
+	fulcrumClient := mocks.NewFulcrumClient(t)
+	fulcrumClient.EXPECT().UpdateAgentStatus(mock.Anything, DefaultStatuses().Error).Return(nil)
+	hung := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	checks := map[string]Check{"pmanager": withTimeout(hung, 10*time.Millisecond)}
+	heartbeat := NewHeartbeat(fulcrumClient, checks, DefaultStatuses(), monitor.NoopMonitor{})
+
+	require.NoError(t, heartbeat.Beat(context.Background()))
+	assert.Equal(t, DefaultStatuses().Error, heartbeat.Reported())
+}
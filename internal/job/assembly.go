@@ -1,30 +1,64 @@
 package job
 
 import (
+	"context"
 	"github.com/metaform/cfm-fulcrum/internal/client"
+	"github.com/metaform/cfm-fulcrum/internal/registration"
 	"github.com/metaform/connector-fabric-manager/common/system"
+	"math/rand/v2"
 	"time"
 )
 
 type JobServiceAssembly struct {
 	system.DefaultServiceAssembly
-	handler     *JobHandler
-	stopChannel chan struct{}
+	config       Config
+	registry     *ActionRegistry
+	store        JobStore
+	handler      *JobHandler
+	registration *registration.Registration
+	observer     Observer
+	liveness     *Liveness
+	cancel       context.CancelFunc
+	done         chan struct{}
 }
 
 func (a *JobServiceAssembly) Name() string {
 	return "Job Service"
 }
 
+func (d *JobServiceAssembly) Provides() []system.ServiceType {
+	return []system.ServiceType{ActionRegistryKey, LivenessKey}
+}
+
 func (d *JobServiceAssembly) Requires() []system.ServiceType {
-	return []system.ServiceType{client.FulcrumClientKey}
+	return []system.ServiceType{client.FulcrumClientKey, registration.RegistrationKey, ObserverKey}
 }
 
 func (a *JobServiceAssembly) Init(context *system.InitContext) error {
+	a.config = loadConfig(context)
+	if err := a.config.Validate(); err != nil {
+		return err
+	}
+
+	// Handlers are contributed by assemblies that require the registry and are initialized after this assembly
+	a.registry = NewActionRegistry()
+	context.Registry.Register(ActionRegistryKey, a.registry)
+
+	store, err := newJobStore(a.config)
+	if err != nil {
+		return err
+	}
+	a.store = store
+
 	fulcrumClient := context.Registry.Resolve(client.FulcrumClientKey).(client.FulcrumClient)
-	apiClient := context.Registry.Resolve(client.ApiClientKey).(client.ApiClient)
+	a.registration = context.Registry.Resolve(registration.RegistrationKey).(*registration.Registration)
+	a.observer = context.Registry.Resolve(ObserverKey).(Observer)
+
+	a.liveness = loadLiveness(context, a.config)
+	context.Registry.Register(LivenessKey, a.liveness)
 
-	a.handler = NewJobHandler(fulcrumClient, apiClient, context.LogMonitor)
+	a.handler = NewJobHandler(fulcrumClient, a.registry, a.store, a.config, context.LogMonitor,
+		WithObserver(a.observer), WithLiveness(a.liveness))
 	return nil
 }
 
@@ -32,19 +66,48 @@ func (a *JobServiceAssembly) Start(ctx *system.StartContext) error {
 	if a.handler == nil {
 		return nil
 	}
-	a.stopChannel = make(chan struct{})
+	for _, action := range client.JobActions {
+		if _, found := a.registry.Resolve(action); !found {
+			ctx.LogMonitor.Warnf("No handler registered for job action %s, jobs of this type will fail", action)
+		}
+	}
+	pollCtx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
+	a.done = make(chan struct{})
 
 	go func() {
-		ticker := time.NewTicker(30 * time.Second) // TODO configure
-		defer ticker.Stop()
+		defer close(a.done)
+		recovered := false
+		delay := a.nextPollDelay()
+		due := time.Now().Add(delay)
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
 		for {
 			select {
-			case <-ticker.C:
-				ctx.LogMonitor.Infof("Polling jobs")
-				if err := a.handler.PollAndProcessJobs(); err != nil {
-					ctx.LogMonitor.Infof("Error polling jobs: %w", err)
+			case <-timer.C:
+				a.liveness.beginCycle(time.Now())
+				a.observer.ObservePoll(time.Since(due))
+				if !a.registration.Verified() {
+					// jobs are not processed until the agent token is known to be valid
+					ctx.LogMonitor.Debugf("Agent registration not verified, skipping job poll")
+				} else {
+					if !recovered {
+						// journaled jobs are reported with the agent token too, so they are recovered only once the
+						// registration is verified
+						a.handler.Recover(pollCtx)
+						recovered = true
+					}
+					a.handler.ReportUnreported(pollCtx)
+					ctx.LogMonitor.Infof("Polling jobs")
+					if err := a.handler.PollAndProcessJobs(pollCtx); err != nil {
+						ctx.LogMonitor.Infof("Error polling jobs: %v", err)
+					}
 				}
-			case <-a.stopChannel:
+				a.liveness.endCycle(time.Now())
+				delay = a.nextPollDelay()
+				due = time.Now().Add(delay)
+				timer.Reset(delay)
+			case <-pollCtx.Done():
 				ctx.LogMonitor.Infof("Stopping job service")
 				return
 			}
@@ -54,9 +117,77 @@ func (a *JobServiceAssembly) Start(ctx *system.StartContext) error {
 }
 
 func (a *JobServiceAssembly) Finalize() error {
-	if a.handler == nil || a.stopChannel == nil {
+	if a.handler == nil || a.cancel == nil {
 		return nil
 	}
-	a.stopChannel <- struct{}{}
+	// Stop accepting jobs first so a poll cycle waiting for a free worker returns, then cancel in-flight Fulcrum calls
+	// made by the poll loop
+	a.handler.Shutdown()
+	a.cancel()
+	<-a.done
 	return nil
 }
+
+func (a *JobServiceAssembly) Shutdown() error {
+	if a.store == nil {
+		return nil
+	}
+	return a.store.Close()
+}
+
+// nextPollDelay returns the poll interval plus a random jitter so that multiple agents do not poll in lockstep
+func (a *JobServiceAssembly) nextPollDelay() time.Duration {
+	if a.config.PollJitter <= 0 {
+		return a.config.PollInterval
+	}
+	return a.config.PollInterval + rand.N(a.config.PollJitter)
+}
+
+func loadConfig(context *system.InitContext) Config {
+	config := DefaultConfig()
+	if context.Config.IsSet(pollIntervalKey) {
+		config.PollInterval = context.Config.GetDuration(pollIntervalKey)
+	}
+	if context.Config.IsSet(pollJitterKey) {
+		config.PollJitter = context.Config.GetDuration(pollJitterKey)
+	}
+	config.MaxJobsPerCycle = context.GetConfigIntOrDefault(maxJobsPerCycleKey, config.MaxJobsPerCycle)
+	config.Workers = context.GetConfigIntOrDefault(workersKey, config.Workers)
+	if context.Config.IsSet(shutdownTimeoutKey) {
+		config.ShutdownTimeout = context.Config.GetDuration(shutdownTimeoutKey)
+	}
+	if context.Config.IsSet(priorityAgingKey) {
+		config.PriorityAging = context.Config.GetDuration(priorityAgingKey)
+	}
+	config.MaxAttempts = context.GetConfigIntOrDefault(maxAttemptsKey, config.MaxAttempts)
+	if context.Config.IsSet(initialBackoffKey) {
+		config.InitialBackoff = context.Config.GetDuration(initialBackoffKey)
+	}
+	if context.Config.IsSet(maxBackoffKey) {
+		config.MaxBackoff = context.Config.GetDuration(maxBackoffKey)
+	}
+	config.StoreType = context.GetConfigStrOrDefault(storeTypeKey, config.StoreType)
+	config.StorePath = context.GetConfigStrOrDefault(storePathKey, config.StorePath)
+	return config
+}
+
+// loadLiveness creates the poll loop liveness tracker. By default the loop may miss two poll intervals before it is
+// considered stuck.
+func loadLiveness(context *system.InitContext, config Config) *Liveness {
+	maxIdle := 3 * (config.PollInterval + config.PollJitter)
+	if context.Config.IsSet(livenessMaxIdleKey) {
+		maxIdle = context.Config.GetDuration(livenessMaxIdleKey)
+	}
+	maxCycle := defaultLivenessCycle
+	if context.Config.IsSet(livenessMaxCycleKey) {
+		maxCycle = context.Config.GetDuration(livenessMaxCycleKey)
+	}
+	return NewLiveness(maxIdle, maxCycle)
+}
+
+func newJobStore(config Config) (JobStore, error) {
+	if config.StoreType == StoreTypeFile {
+		return NewFileJobStore(config.StorePath)
+	}
+	return NewMemoryJobStore(), nil
+}
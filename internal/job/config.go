@@ -0,0 +1,148 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package job
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	pollIntervalKey     = "job.poll_interval"
+	pollJitterKey       = "job.poll_jitter"
+	maxJobsPerCycleKey  = "job.max_jobs"
+	workersKey          = "job.workers"
+	shutdownTimeoutKey  = "job.shutdown_timeout"
+	priorityAgingKey    = "job.priority_aging"
+	maxAttemptsKey      = "job.retry.max_attempts"
+	initialBackoffKey   = "job.retry.initial_backoff"
+	maxBackoffKey       = "job.retry.max_backoff"
+	storeTypeKey        = "job.store.type"
+	storePathKey        = "job.store.path"
+	livenessMaxIdleKey  = "job.liveness.max_idle"
+	livenessMaxCycleKey = "job.liveness.max_cycle"
+
+	StoreTypeMemory = "memory"
+	StoreTypeFile   = "file"
+
+	defaultPollInterval    = 30 * time.Second
+	defaultPollJitter      = 0 * time.Second
+	defaultMaxJobsPerCycle = 10
+	defaultWorkers         = 4
+	defaultShutdownTimeout = 30 * time.Second
+	defaultPriorityAging   = 5 * time.Minute
+	defaultMaxAttempts     = 5
+	defaultInitialBackoff  = 1 * time.Second
+	defaultMaxBackoff      = 1 * time.Minute
+	defaultStoreType       = StoreTypeMemory
+	defaultLivenessCycle   = 15 * time.Minute
+)
+
+// Config contains the settings that control how jobs are polled from Fulcrum Core
+type Config struct {
+	// PollInterval is the base delay between two poll cycles
+	PollInterval time.Duration
+	// PollJitter is the upper bound of a random delay added to each poll interval
+	PollJitter time.Duration
+	// MaxJobsPerCycle is the maximum number of pending jobs handled in a single poll cycle
+	MaxJobsPerCycle int
+	// Workers is the number of jobs that may be processed concurrently
+	Workers int
+	// ShutdownTimeout is how long shutdown waits for in-flight jobs before cancelling them
+	ShutdownTimeout time.Duration
+	// PriorityAging is the pending time after which a job's priority is raised by one. Zero disables aging, in which
+	// case jobs are selected strictly by priority.
+	PriorityAging time.Duration
+	// MaxAttempts is the number of times a job is processed before a retryable failure fails the job
+	MaxAttempts int
+	// InitialBackoff is the delay before the second attempt. The delay doubles with every further attempt.
+	InitialBackoff time.Duration
+	// MaxBackoff is the upper bound of the delay between attempts
+	MaxBackoff time.Duration
+	// StoreType selects the JobStore implementation used to journal jobs, either StoreTypeMemory or StoreTypeFile
+	StoreType string
+	// StorePath is the journal file used by the file store
+	StorePath string
+}
+
+// DefaultConfig returns the configuration used when no job settings are specified
+func DefaultConfig() Config {
+	return Config{
+		PollInterval:    defaultPollInterval,
+		PollJitter:      defaultPollJitter,
+		MaxJobsPerCycle: defaultMaxJobsPerCycle,
+		Workers:         defaultWorkers,
+		ShutdownTimeout: defaultShutdownTimeout,
+		PriorityAging:   defaultPriorityAging,
+		MaxAttempts:     defaultMaxAttempts,
+		InitialBackoff:  defaultInitialBackoff,
+		MaxBackoff:      defaultMaxBackoff,
+		StoreType:       defaultStoreType,
+	}
+}
+
+// Validate checks that the configuration values are usable
+func (c Config) Validate() error {
+	if c.PollInterval <= 0 {
+		return fmt.Errorf("%s must be greater than 0, was: %s", pollIntervalKey, c.PollInterval)
+	}
+	if c.PollJitter < 0 {
+		return fmt.Errorf("%s must not be negative, was: %s", pollJitterKey, c.PollJitter)
+	}
+	if c.PollJitter >= c.PollInterval {
+		return fmt.Errorf("%s (%s) must be less than %s (%s)", pollJitterKey, c.PollJitter, pollIntervalKey, c.PollInterval)
+	}
+	if c.MaxJobsPerCycle < 1 {
+		return fmt.Errorf("%s must be at least 1, was: %d", maxJobsPerCycleKey, c.MaxJobsPerCycle)
+	}
+	if c.Workers < 1 {
+		return fmt.Errorf("%s must be at least 1, was: %d", workersKey, c.Workers)
+	}
+	if c.ShutdownTimeout < 0 {
+		return fmt.Errorf("%s must not be negative, was: %s", shutdownTimeoutKey, c.ShutdownTimeout)
+	}
+	if c.PriorityAging < 0 {
+		return fmt.Errorf("%s must not be negative, was: %s", priorityAgingKey, c.PriorityAging)
+	}
+	if c.MaxAttempts < 1 {
+		return fmt.Errorf("%s must be at least 1, was: %d", maxAttemptsKey, c.MaxAttempts)
+	}
+	if c.InitialBackoff < 0 {
+		return fmt.Errorf("%s must not be negative, was: %s", initialBackoffKey, c.InitialBackoff)
+	}
+	if c.MaxBackoff < c.InitialBackoff {
+		return fmt.Errorf("%s (%s) must not be less than %s (%s)", maxBackoffKey, c.MaxBackoff, initialBackoffKey, c.InitialBackoff)
+	}
+	switch c.StoreType {
+	case StoreTypeMemory:
+	case StoreTypeFile:
+		if c.StorePath == "" {
+			return fmt.Errorf("%s is required when %s is %s", storePathKey, storeTypeKey, StoreTypeFile)
+		}
+	default:
+		return fmt.Errorf("%s must be %s or %s, was: %s", storeTypeKey, StoreTypeMemory, StoreTypeFile, c.StoreType)
+	}
+	return nil
+}
+
+// backoff returns the delay before the given attempt, starting with attempt 2
+func (c Config) backoff(attempt int) time.Duration {
+	delay := c.InitialBackoff
+	for i := 2; i < attempt; i++ {
+		delay *= 2
+		if delay >= c.MaxBackoff {
+			return c.MaxBackoff
+		}
+	}
+	return min(delay, c.MaxBackoff)
+}
@@ -0,0 +1,91 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/metaform/cfm-fulcrum/internal/client"
+	"net"
+	"strings"
+)
+
+// Error is a job processing error that is explicitly classified as retryable or permanent
+type Error struct {
+	Err       error
+	Retryable bool
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NewRetryableError marks an error as transient, so the job is processed again
+func NewRetryableError(err error) error {
+	return &Error{Err: err, Retryable: true}
+}
+
+// NewPermanentError marks an error as permanent, so the job is failed without further attempts
+func NewPermanentError(err error) error {
+	return &Error{Err: err, Retryable: false}
+}
+
+// IsRetryable returns true if processing a job again may succeed after the error.
+//
+// Errors explicitly classified with NewRetryableError or NewPermanentError keep their classification. Otherwise,
+// network errors and upstream responses the client marks as retryable (HTTP 408, 429 and 5xx except 501) are
+// retryable. All other errors, including other 4xx responses, 501 and cancellation, are permanent.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var jobErr *Error
+	if errors.As(err, &jobErr) {
+		return jobErr.Retryable
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var httpErr *client.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Retryable
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// attemptsError reports the error of every attempt made to process a job
+type attemptsError struct {
+	attempts []error
+}
+
+func (e *attemptsError) Error() string {
+	if len(e.attempts) == 1 {
+		return e.attempts[0].Error()
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "failed after %d attempts", len(e.attempts))
+	for i, err := range e.attempts {
+		fmt.Fprintf(&sb, "; attempt %d: %v", i+1, err)
+	}
+	return sb.String()
+}
+
+func (e *attemptsError) Unwrap() []error {
+	return e.attempts
+}
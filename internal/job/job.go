@@ -13,29 +13,55 @@
 package job
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/google/uuid"
 	"github.com/metaform/cfm-fulcrum/internal/client"
 	"github.com/metaform/connector-fabric-manager/common/monitor"
-	"github.com/metaform/connector-fabric-manager/pmanager/api"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // JobHandler processes jobs from the Fulcrum Core job queue
 type JobHandler struct {
 	fulcrumClient client.FulcrumClient
-	apiClient     client.ApiClient
+	registry      *ActionRegistry
+	store         JobStore
+	config        Config
 	monitor       monitor.LogMonitor
+	observer      Observer
+	liveness      *Liveness
+	pool          *workerPool
 	stats         struct {
-		processed int
-		succeeded int
-		failed    int
+		processed atomic.Int64
+		succeeded atomic.Int64
+		failed    atomic.Int64
 	}
+	unreported struct {
+		sync.Mutex
+		results map[string]unreportedResult
+	}
+}
+
+// unreportedResult is a journaled job result that could not be reported to Fulcrum Core because of a retryable error
+type unreportedResult struct {
+	entry *JournalEntry
+	resp  any
 }
 
 // JobResources represents the resources in a job response
 type JobResources struct {
-	TS time.Time `json:"ts"`
+	TS             time.Time         `json:"ts"`
+	StartedAt      *time.Time        `json:"startedAt,omitempty"`
+	DeploymentID   string            `json:"deploymentId,omitempty"`
+	DeploymentType string            `json:"deploymentType,omitempty"`
+	Endpoints      map[string]string `json:"endpoints,omitempty"`
 }
 
 // JobResponse represents the response for a job
@@ -50,18 +76,47 @@ type VMProps struct {
 }
 
 // NewJobHandler creates a new job handler
-func NewJobHandler(fulcrumClient client.FulcrumClient, apiClient client.ApiClient, monitor monitor.LogMonitor) *JobHandler {
-	return &JobHandler{
+func NewJobHandler(
+	fulcrumClient client.FulcrumClient,
+	registry *ActionRegistry,
+	store JobStore,
+	config Config,
+	monitor monitor.LogMonitor,
+	options ...JobHandlerOption) *JobHandler {
+	handler := &JobHandler{
 		fulcrumClient: fulcrumClient,
-		apiClient:     apiClient,
+		registry:      registry,
+		store:         store,
+		config:        config,
 		monitor:       monitor,
+		observer:      noopObserver{},
+		pool:          newWorkerPool(config.Workers),
+	}
+	for _, option := range options {
+		option(handler)
+	}
+	return handler
+}
+
+// PollAndProcessJobs polls for pending jobs and hands up to the configured maximum number of jobs per cycle to the
+// worker pool, most urgent first. A job is only claimed once a worker is available to process it.
+//
+// Each poll cycle is traced. The span of each job is a child of the poll span and lasts from claiming the job to
+// reporting its result.
+func (h *JobHandler) PollAndProcessJobs(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "job.poll")
+	defer span.End()
+	err := h.pollAndProcessJobs(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 	}
+	return err
 }
 
-// PollAndProcessJobs polls for pending jobs and processes them
-func (h *JobHandler) PollAndProcessJobs() error {
+func (h *JobHandler) pollAndProcessJobs(ctx context.Context) error {
 	// Get pending jobs
-	jobs, err := h.fulcrumClient.GetPendingJobs()
+	jobs, err := h.fulcrumClient.GetPendingJobs(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get pending jobs: %w", err)
 	}
@@ -70,64 +125,255 @@ func (h *JobHandler) PollAndProcessJobs() error {
 		h.monitor.Infof("Pending jobs not found")
 		return nil
 	}
-	job := jobs[0]
-	h.stats.processed++
+	orderJobs(jobs, h.config.PriorityAging, time.Now())
+	if len(jobs) > h.config.MaxJobsPerCycle {
+		jobs = jobs[:h.config.MaxJobsPerCycle]
+	}
 
-	// Claim the job
-	if err := h.fulcrumClient.ClaimJob(job.ID); err != nil {
-		h.stats.failed++
-		return err
+	var errs []error
+	for _, job := range jobs {
+		if !h.acquire() {
+			// shutting down, leave remaining jobs pending
+			break
+		}
+
+		// Claim the job
+		jobCtx, _ := tracer.Start(ctx, "job.process", trace.WithAttributes(jobAttributes(job)...))
+		if err := h.fulcrumClient.ClaimJob(jobCtx, job.ID); err != nil {
+			h.pool.release()
+			if client.HasStatus(err, http.StatusConflict) {
+				h.monitor.Infof("Job %s already claimed by another agent", job.ID)
+				h.finish(jobCtx, job, OutcomeConflict, time.Time{}, nil)
+				continue
+			}
+			h.finish(jobCtx, job, OutcomeClaimFailed, time.Time{}, err)
+			h.stats.processed.Add(1)
+			h.stats.failed.Add(1)
+			errs = append(errs, fmt.Errorf("job %s: %w", job.ID, err))
+			continue
+		}
+		h.stats.processed.Add(1)
+		claimedAt := time.Now()
+		h.journal(&JournalEntry{Job: job, State: EntryStateClaimed})
+
+		span := trace.SpanFromContext(jobCtx)
+		h.pool.submit(func(ctx context.Context) {
+			if err := h.handleJob(trace.ContextWithSpan(ctx, span), job, claimedAt); err != nil {
+				h.monitor.Severef("Error handling job %s: %v", job.ID, err)
+			}
+		})
 	}
+	return errors.Join(errs...)
+}
+
+// Recover finishes jobs that were journaled but not reported to Fulcrum Core when the agent last stopped. Jobs with a
+// recorded result have the result reported again. All other jobs are processed again, which is safe because action
+// handlers are idempotent for a given job.
+func (h *JobHandler) Recover(ctx context.Context) {
+	entries, err := h.store.ListUnfinished()
+	if err != nil {
+		h.monitor.Severef("Unable to read job journal: %v", err)
+		return
+	}
+	if len(entries) > 0 {
+		h.monitor.Infof("Recovering %d unfinished job(s)", len(entries))
+	}
+	for _, entry := range entries {
+		switch entry.State {
+		case EntryStateSucceeded, EntryStateFailed:
+			if err := h.report(ctx, entry, entry.Response); err != nil {
+				h.monitor.Severef("Error reporting recovered job %s: %v", entry.Job.ID, err)
+			}
+		default:
+			if !h.acquire() {
+				return
+			}
+			job := entry.Job
+			_, span := tracer.Start(ctx, "job.process", trace.WithAttributes(jobAttributes(job)...),
+				trace.WithAttributes(attribute.Bool("cfm.job.recovered", true)))
+			h.pool.submit(func(ctx context.Context) {
+				// the claim time is not journaled, so no latency is recorded for recovered jobs
+				if err := h.handleJob(trace.ContextWithSpan(ctx, span), job, time.Time{}); err != nil {
+					h.monitor.Severef("Error handling recovered job %s: %v", job.ID, err)
+				}
+			})
+		}
+	}
+}
+
+// ReportUnreported reports job results again that could not be reported to Fulcrum Core because of a retryable error.
+// It is called on each poll cycle, so results are retried while the agent keeps running and not only when it restarts.
+func (h *JobHandler) ReportUnreported(ctx context.Context) {
+	h.unreported.Lock()
+	results := h.unreported.results
+	h.unreported.results = nil
+	h.unreported.Unlock()
+
+	for _, result := range results {
+		if err := h.report(ctx, result.entry, result.resp); err != nil {
+			h.monitor.Warnf("Error reporting result of job %s: %v", result.entry.Job.ID, err)
+		}
+	}
+}
+
+// Shutdown stops accepting new jobs and waits for in-flight jobs to finish. Jobs still running when the configured
+// shutdown timeout elapses are cancelled.
+func (h *JobHandler) Shutdown() {
+	if !h.pool.shutdown(h.config.ShutdownTimeout) {
+		h.monitor.Warnf("In-flight jobs were cancelled after waiting %s for them to complete", h.config.ShutdownTimeout)
+	}
+}
+
+// acquire waits for a worker slot. The wait is not counted as poll cycle time by the liveness tracker, since workers
+// may legitimately be busy with long-running jobs.
+func (h *JobHandler) acquire() bool {
+	if h.liveness == nil {
+		return h.pool.acquire()
+	}
+	h.liveness.beginWait()
+	defer h.liveness.endWait(time.Now())
+	return h.pool.acquire()
+}
+
+// handleJob processes a claimed job and reports the outcome to Fulcrum Core. The job span in the context is ended
+// once the outcome is reported. If processing is cancelled, the job is not reported but kept in the journal as
+// dispatched, so that it is processed again when the agent restarts.
+func (h *JobHandler) handleJob(ctx context.Context, job *client.Job, claimedAt time.Time) error {
+	h.journal(&JournalEntry{Job: job, State: EntryStateDispatched})
 
 	// Process the job
-	resp, err := h.processJob(job)
+	resp, err := h.processWithRetry(ctx, job)
+	if err != nil && ctx.Err() != nil {
+		h.monitor.Warnf("Processing of job %s was cancelled, it will be recovered on restart: %v", job.ID, err)
+		h.finish(ctx, job, OutcomeCancelled, claimedAt, err)
+		return nil
+	}
 	if err != nil {
-		// Mark job as failed
-		h.stats.failed++
-		if failErr := h.fulcrumClient.FailJob(job.ID, err.Error()); failErr != nil {
-			//	log.Printf("Failed to mark job %s as failed: %v", job.ID, failErr)
-			return failErr
+		h.stats.failed.Add(1)
+		entry := &JournalEntry{Job: job, State: EntryStateFailed, Error: err.Error()}
+		h.journal(entry)
+		reportErr := h.report(ctx, entry, nil)
+		h.finish(ctx, job, OutcomeFailed, claimedAt, errors.Join(err, reportErr))
+		return reportErr
+	}
+
+	// Job succeeded
+	if resp == nil {
+		resp = &JobResponse{
+			Resources:  JobResources{TS: time.Now().UTC()},
+			ExternalID: job.Service.ExternalID,
 		}
+	}
+	entry := &JournalEntry{Job: job, State: EntryStateSucceeded}
+	if entry.Response, err = json.Marshal(resp); err != nil {
+		h.monitor.Warnf("Unable to journal response of job %s: %v", job.ID, err)
+	}
+	h.journal(entry)
+	if err := h.report(ctx, entry, resp); err != nil {
+		h.finish(ctx, job, OutcomeFailed, claimedAt, err)
+		return err
+	}
+	h.stats.succeeded.Add(1)
+	h.finish(ctx, job, OutcomeSucceeded, claimedAt, nil)
+	return nil
+}
+
+// finish ends the job span in the context and notifies the observer of the outcome
+func (h *JobHandler) finish(ctx context.Context, job *client.Job, outcome Outcome, claimedAt time.Time, err error) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(OutcomeAttribute.String(string(outcome)))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+
+	var duration time.Duration
+	if !claimedAt.IsZero() {
+		duration = time.Since(claimedAt)
+	}
+	h.observer.ObserveJob(job.Action, outcome, duration)
+}
+
+// report sends the journaled result of a job to Fulcrum Core and removes the job from the journal. If reporting fails
+// with a retryable error, the entry is kept so that reporting is attempted again by ReportUnreported or, if the agent
+// stops first, when it restarts.
+//
+// The result is reported even if the context was cancelled, since the job has already been processed.
+func (h *JobHandler) report(ctx context.Context, entry *JournalEntry, resp any) error {
+	ctx = context.WithoutCancel(ctx)
+	var err error
+	if entry.State == EntryStateFailed {
+		// Mark job as failed
+		err = h.fulcrumClient.FailJob(ctx, entry.Job.ID, entry.Error)
 	} else {
-		// Job succeeded
-		if complErr := h.fulcrumClient.CompleteJob(job.ID, resp); complErr != nil {
-			//	log.Printf("Failed to mark job %s as completed: %v", job.ID, complErr)
-			return complErr
+		err = h.fulcrumClient.CompleteJob(ctx, entry.Job.ID, resp)
+	}
+	if err != nil && IsRetryable(err) {
+		h.unreported.Lock()
+		if h.unreported.results == nil {
+			h.unreported.results = make(map[string]unreportedResult)
 		}
-		h.stats.succeeded++
+		h.unreported.results[entry.Job.ID] = unreportedResult{entry: entry, resp: resp}
+		h.unreported.Unlock()
+		return err
 	}
+	if deleteErr := h.store.Delete(entry.Job.ID); deleteErr != nil {
+		h.monitor.Warnf("Unable to remove job %s from journal: %v", entry.Job.ID, deleteErr)
+	}
+	return err
+}
 
-	return nil
+// journal records a job state transition. Journaling is best-effort: a failure is logged and processing continues.
+func (h *JobHandler) journal(entry *JournalEntry) {
+	entry.UpdatedAt = time.Now().UTC()
+	if err := h.store.Save(entry); err != nil {
+		h.monitor.Severef("Unable to journal job %s as %s: %v", entry.Job.ID, entry.State, err)
+	}
 }
 
-// processJob processes a job based on its type
-func (h *JobHandler) processJob(job *client.Job) (any, error) {
-	switch job.Action {
-	case client.JobActionServiceCreate:
-	case client.JobActionServiceColdUpdate, client.JobActionServiceHotUpdate:
-	case client.JobActionServiceStart:
-	case client.JobActionServiceStop:
-	case client.JobActionServiceDelete:
-	default:
-		return nil, fmt.Errorf("unknown job type: %s", job.Action)
+// processWithRetry processes a job, retrying retryable failures with exponential backoff until the maximum number of
+// attempts is reached. The returned error contains the error of every attempt.
+func (h *JobHandler) processWithRetry(ctx context.Context, job *client.Job) (any, error) {
+	var attempts []error
+	for attempt := 1; ; attempt++ {
+		resp, err := h.processJob(ctx, job)
+		if err == nil {
+			return resp, nil
+		}
+		attempts = append(attempts, err)
+
+		if !IsRetryable(err) || attempt >= h.config.MaxAttempts {
+			return nil, &attemptsError{attempts: attempts}
+		}
+
+		delay := h.config.backoff(attempt + 1)
+		h.monitor.Warnf("Attempt %d of job %s failed, retrying in %s: %v", attempt, job.ID, delay, err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			attempts = append(attempts, fmt.Errorf("job processing cancelled: %w", ctx.Err()))
+			return nil, &attemptsError{attempts: attempts}
+		}
 	}
+}
 
-	requestBody := api.DeploymentManifest{
-		DeploymentType: "test.deployment",
-		ID:             uuid.New().String(),
-		Payload:        make(map[string]any),
+// processJob dispatches a job to the handler registered for its action
+func (h *JobHandler) processJob(ctx context.Context, job *client.Job) (any, error) {
+	handler, found := h.registry.Resolve(job.Action)
+	if !found {
+		return nil, fmt.Errorf("no handler registered for job action: %s", job.Action)
 	}
 
-	fmt.Printf("Processing job %s of type %s", job.ID, job.Action)
-	err := h.apiClient.PostToPManager("deployment", requestBody)
-	if err != nil {
-		h.monitor.Severef("**********error in job handler **********: %w", err)
-		return nil, err
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("job processing cancelled: %w", err)
 	}
-	return nil, nil
+
+	h.monitor.Infof("Processing job %s of type %s", job.ID, job.Action)
+	return handler.Handle(ctx, job)
 }
 
 // GetStats returns the job processing statistics
 func (h *JobHandler) GetStats() (processed, succeeded, failed int) {
-	return h.stats.processed, h.stats.succeeded, h.stats.failed
+	return int(h.stats.processed.Load()), int(h.stats.succeeded.Load()), int(h.stats.failed.Load())
 }
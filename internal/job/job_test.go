@@ -0,0 +1,582 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/metaform/cfm-fulcrum/internal/client"
+	"github.com/metaform/cfm-fulcrum/internal/client/mocks"
+	"github.com/metaform/connector-fabric-manager/common/monitor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	require.NoError(t, DefaultConfig().Validate())
+
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+	}{
+		{"zero interval", func(c *Config) { c.PollInterval = 0 }},
+		{"negative jitter", func(c *Config) { c.PollJitter = -time.Second }},
+		{"jitter exceeds interval", func(c *Config) { c.PollJitter = c.PollInterval }},
+		{"zero max jobs", func(c *Config) { c.MaxJobsPerCycle = 0 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			tt.modify(&config)
+			assert.Error(t, config.Validate())
+		})
+	}
+}
+
+func TestPollAndProcessJobs_MaxJobsPerCycle(t *testing.T) {
+	fulcrumClient := mocks.NewFulcrumClient(t)
+
+	jobs := make([]*client.Job, 5)
+	for i := range jobs {
+		jobs[i] = &client.Job{ID: fmt.Sprintf("job-%d", i), Action: client.JobActionServiceCreate}
+	}
+	fulcrumClient.EXPECT().GetPendingJobs(mock.Anything).Return(jobs, nil)
+	fulcrumClient.EXPECT().ClaimJob(mock.Anything, mock.Anything).Return(nil).Times(3)
+	fulcrumClient.EXPECT().CompleteJob(mock.Anything, mock.Anything, mock.Anything).Return(nil).Times(3)
+
+	config := DefaultConfig()
+	config.MaxJobsPerCycle = 3
+	handler := NewJobHandler(fulcrumClient, newTestRegistry(noopHandler), NewMemoryJobStore(), config, monitor.NoopMonitor{})
+
+	require.NoError(t, handler.PollAndProcessJobs(context.Background()))
+	handler.Shutdown()
+
+	processed, succeeded, failed := handler.GetStats()
+	assert.Equal(t, 3, processed)
+	assert.Equal(t, 3, succeeded)
+	assert.Equal(t, 0, failed)
+}
+
+func TestPollAndProcessJobs_ContinuesAfterClaimFailure(t *testing.T) {
+	fulcrumClient := mocks.NewFulcrumClient(t)
+
+	jobs := []*client.Job{
+		{ID: "job-1", Action: client.JobActionServiceCreate},
+		{ID: "job-2", Action: client.JobActionServiceCreate},
+	}
+	fulcrumClient.EXPECT().GetPendingJobs(mock.Anything).Return(jobs, nil)
+	fulcrumClient.EXPECT().ClaimJob(mock.Anything, "job-1").Return(fmt.Errorf("unavailable"))
+	fulcrumClient.EXPECT().ClaimJob(mock.Anything, "job-2").Return(nil)
+	// handlers that return no response still complete the job with a response body
+	fulcrumClient.EXPECT().CompleteJob(mock.Anything, "job-2", mock.MatchedBy(func(response any) bool {
+		jobResponse, ok := response.(*JobResponse)
+		return ok && !jobResponse.Resources.TS.IsZero()
+	})).Return(nil)
+
+	handler := NewJobHandler(fulcrumClient, newTestRegistry(noopHandler), NewMemoryJobStore(), DefaultConfig(), monitor.NoopMonitor{})
+
+	err := handler.PollAndProcessJobs(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "job-1")
+	handler.Shutdown()
+
+	processed, succeeded, failed := handler.GetStats()
+	assert.Equal(t, 2, processed)
+	assert.Equal(t, 1, succeeded)
+	assert.Equal(t, 1, failed)
+}
+
+func TestPollAndProcessJobs_SkipsJobClaimedByAnotherAgent(t *testing.T) {
+	fulcrumClient := mocks.NewFulcrumClient(t)
+
+	jobs := []*client.Job{{ID: "job-1", Action: client.JobActionServiceCreate}}
+	fulcrumClient.EXPECT().GetPendingJobs(mock.Anything).Return(jobs, nil)
+	fulcrumClient.EXPECT().ClaimJob(mock.Anything, "job-1").
+		Return(fmt.Errorf("failed to claim job: %w", httpErr(http.StatusConflict)))
+
+	handler := NewJobHandler(fulcrumClient, newTestRegistry(noopHandler), NewMemoryJobStore(), DefaultConfig(), monitor.NoopMonitor{})
+
+	err := handler.PollAndProcessJobs(context.Background())
+	require.NoError(t, err)
+	handler.Shutdown()
+
+	processed, _, failed := handler.GetStats()
+	assert.Equal(t, 0, processed)
+	assert.Equal(t, 0, failed)
+}
+
+func TestPollAndProcessJobs_BoundedConcurrency(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	slowHandler := ActionHandlerFunc(func(ctx context.Context, job *client.Job) (any, error) {
+		current := inFlight.Add(1)
+		for {
+			highest := maxInFlight.Load()
+			if current <= highest || maxInFlight.CompareAndSwap(highest, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		inFlight.Add(-1)
+		return nil, nil
+	})
+
+	fulcrumClient := mocks.NewFulcrumClient(t)
+	jobs := make([]*client.Job, 6)
+	for i := range jobs {
+		jobs[i] = &client.Job{ID: fmt.Sprintf("job-%d", i), Action: client.JobActionServiceCreate}
+	}
+	fulcrumClient.EXPECT().GetPendingJobs(mock.Anything).Return(jobs, nil)
+	fulcrumClient.EXPECT().ClaimJob(mock.Anything, mock.Anything).Return(nil).Times(6)
+	fulcrumClient.EXPECT().CompleteJob(mock.Anything, mock.Anything, mock.Anything).Return(nil).Times(6)
+
+	config := DefaultConfig()
+	config.Workers = 2
+	handler := NewJobHandler(fulcrumClient, newTestRegistry(slowHandler), NewMemoryJobStore(), config, monitor.NoopMonitor{})
+
+	require.NoError(t, handler.PollAndProcessJobs(context.Background()))
+	handler.Shutdown()
+
+	_, succeeded, _ := handler.GetStats()
+	assert.Equal(t, 6, succeeded)
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(2))
+}
+
+func TestPollAndProcessJobs_AfterShutdown(t *testing.T) {
+	fulcrumClient := mocks.NewFulcrumClient(t)
+	fulcrumClient.EXPECT().GetPendingJobs(mock.Anything).Return([]*client.Job{{ID: "job-1"}}, nil)
+
+	handler := NewJobHandler(fulcrumClient, newTestRegistry(noopHandler), NewMemoryJobStore(), DefaultConfig(), monitor.NoopMonitor{})
+	handler.Shutdown()
+
+	// the job must not be claimed once the handler no longer accepts work
+	require.NoError(t, handler.PollAndProcessJobs(context.Background()))
+}
+
+func TestOrderJobs(t *testing.T) {
+	now := time.Now()
+	jobs := []*client.Job{
+		{ID: "low-new", Priority: 1, CreatedAt: now.Add(-time.Minute)},
+		{ID: "high-new", Priority: 5, CreatedAt: now.Add(-time.Minute)},
+		{ID: "high-old", Priority: 5, CreatedAt: now.Add(-2 * time.Minute)},
+		{ID: "low-starved", Priority: 1, CreatedAt: now.Add(-time.Hour)},
+	}
+
+	t.Run("strict priority", func(t *testing.T) {
+		ordered := append([]*client.Job(nil), jobs...)
+		orderJobs(ordered, 0, now)
+		assert.Equal(t, []string{"high-old", "high-new", "low-starved", "low-new"}, jobIDs(ordered))
+	})
+
+	t.Run("aging", func(t *testing.T) {
+		ordered := append([]*client.Job(nil), jobs...)
+		orderJobs(ordered, 10*time.Minute, now)
+		assert.Equal(t, []string{"low-starved", "high-old", "high-new", "low-new"}, jobIDs(ordered))
+	})
+
+	t.Run("missing creation time", func(t *testing.T) {
+		ordered := []*client.Job{
+			{ID: "unknown-1", Priority: 5},
+			{ID: "new", Priority: 5, CreatedAt: now.Add(-time.Minute)},
+			{ID: "unknown-2", Priority: 5},
+			{ID: "old", Priority: 5, CreatedAt: now.Add(-time.Hour)},
+			{ID: "low", Priority: 1, CreatedAt: now.Add(-time.Hour)},
+			{ID: "unknown-3", Priority: 5},
+		}
+		orderJobs(ordered, 0, now)
+		assert.Equal(t, []string{"old", "new", "unknown-1", "unknown-2", "unknown-3", "low"}, jobIDs(ordered))
+	})
+}
+
+func jobIDs(jobs []*client.Job) []string {
+	ids := make([]string, len(jobs))
+	for i, job := range jobs {
+		ids[i] = job.ID
+	}
+	return ids
+}
+
+func TestPollAndProcessJobs_UnknownAction(t *testing.T) {
+	fulcrumClient := mocks.NewFulcrumClient(t)
+	fulcrumClient.EXPECT().GetPendingJobs(mock.Anything).Return([]*client.Job{{ID: "job-1", Action: "ServiceReboot"}}, nil)
+	fulcrumClient.EXPECT().ClaimJob(mock.Anything, "job-1").Return(nil)
+	fulcrumClient.EXPECT().FailJob(mock.Anything, "job-1", "no handler registered for job action: ServiceReboot").Return(nil)
+
+	handler := NewJobHandler(fulcrumClient, newTestRegistry(noopHandler), NewMemoryJobStore(), DefaultConfig(), monitor.NoopMonitor{})
+
+	require.NoError(t, handler.PollAndProcessJobs(context.Background()))
+	handler.Shutdown()
+
+	_, _, failed := handler.GetStats()
+	assert.Equal(t, 1, failed)
+}
+
+func TestPollAndProcessJobs_Retry(t *testing.T) {
+	var calls atomic.Int32
+	flakyHandler := ActionHandlerFunc(func(ctx context.Context, job *client.Job) (any, error) {
+		if calls.Add(1) < 3 {
+			return nil, NewRetryableError(errors.New("service unavailable"))
+		}
+		return nil, nil
+	})
+
+	fulcrumClient := mocks.NewFulcrumClient(t)
+	fulcrumClient.EXPECT().GetPendingJobs(mock.Anything).Return([]*client.Job{{ID: "job-1", Action: client.JobActionServiceCreate}}, nil)
+	fulcrumClient.EXPECT().ClaimJob(mock.Anything, "job-1").Return(nil)
+	fulcrumClient.EXPECT().CompleteJob(mock.Anything, "job-1", mock.Anything).Return(nil)
+
+	handler := NewJobHandler(fulcrumClient, newTestRegistry(flakyHandler), NewMemoryJobStore(), newRetryConfig(), monitor.NoopMonitor{})
+
+	require.NoError(t, handler.PollAndProcessJobs(context.Background()))
+	handler.Shutdown()
+
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestPollAndProcessJobs_RetriesExhausted(t *testing.T) {
+	var calls atomic.Int32
+	failingHandler := ActionHandlerFunc(func(ctx context.Context, job *client.Job) (any, error) {
+		return nil, fmt.Errorf("attempt %d: %w", calls.Add(1), NewRetryableError(errors.New("service unavailable")))
+	})
+
+	fulcrumClient := mocks.NewFulcrumClient(t)
+	fulcrumClient.EXPECT().GetPendingJobs(mock.Anything).Return([]*client.Job{{ID: "job-1", Action: client.JobActionServiceCreate}}, nil)
+	fulcrumClient.EXPECT().ClaimJob(mock.Anything, "job-1").Return(nil)
+	fulcrumClient.EXPECT().FailJob(mock.Anything, "job-1", mock.MatchedBy(func(message string) bool {
+		return strings.HasPrefix(message, "failed after 3 attempts") && strings.Contains(message, "attempt 3: attempt 3: service unavailable")
+	})).Return(nil)
+
+	handler := NewJobHandler(fulcrumClient, newTestRegistry(failingHandler), NewMemoryJobStore(), newRetryConfig(), monitor.NoopMonitor{})
+
+	require.NoError(t, handler.PollAndProcessJobs(context.Background()))
+	handler.Shutdown()
+
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestPollAndProcessJobs_PermanentFailure(t *testing.T) {
+	var calls atomic.Int32
+	invalidHandler := ActionHandlerFunc(func(ctx context.Context, job *client.Job) (any, error) {
+		calls.Add(1)
+		return nil, NewPermanentError(errors.New("invalid tenant"))
+	})
+
+	fulcrumClient := mocks.NewFulcrumClient(t)
+	fulcrumClient.EXPECT().GetPendingJobs(mock.Anything).Return([]*client.Job{{ID: "job-1", Action: client.JobActionServiceCreate}}, nil)
+	fulcrumClient.EXPECT().ClaimJob(mock.Anything, "job-1").Return(nil)
+	fulcrumClient.EXPECT().FailJob(mock.Anything, "job-1", "invalid tenant").Return(nil)
+
+	handler := NewJobHandler(fulcrumClient, newTestRegistry(invalidHandler), NewMemoryJobStore(), newRetryConfig(), monitor.NoopMonitor{})
+
+	require.NoError(t, handler.PollAndProcessJobs(context.Background()))
+	handler.Shutdown()
+
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestRecover(t *testing.T) {
+	store := NewMemoryJobStore()
+	require.NoError(t, store.Save(&JournalEntry{Job: &client.Job{ID: "claimed", Action: client.JobActionServiceCreate}, State: EntryStateClaimed}))
+	require.NoError(t, store.Save(&JournalEntry{Job: &client.Job{ID: "succeeded"}, State: EntryStateSucceeded, Response: []byte(`{"externalId":"ext"}`)}))
+	require.NoError(t, store.Save(&JournalEntry{Job: &client.Job{ID: "failed"}, State: EntryStateFailed, Error: "boom"}))
+
+	fulcrumClient := mocks.NewFulcrumClient(t)
+	fulcrumClient.EXPECT().CompleteJob(mock.Anything, "claimed", mock.Anything).Return(nil)
+	fulcrumClient.EXPECT().CompleteJob(mock.Anything, "succeeded", json.RawMessage(`{"externalId":"ext"}`)).Return(nil)
+	fulcrumClient.EXPECT().FailJob(mock.Anything, "failed", "boom").Return(nil)
+
+	handler := NewJobHandler(fulcrumClient, newTestRegistry(noopHandler), store, DefaultConfig(), monitor.NoopMonitor{})
+	handler.Recover(context.Background())
+	handler.Shutdown()
+
+	entries, err := store.ListUnfinished()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestPollAndProcessJobs_KeepsUnreportedResult(t *testing.T) {
+	store := NewMemoryJobStore()
+	fulcrumClient := mocks.NewFulcrumClient(t)
+	fulcrumClient.EXPECT().GetPendingJobs(mock.Anything).Return([]*client.Job{{ID: "job-1", Action: client.JobActionServiceCreate}}, nil)
+	fulcrumClient.EXPECT().ClaimJob(mock.Anything, "job-1").Return(nil)
+	fulcrumClient.EXPECT().CompleteJob(mock.Anything, "job-1", mock.Anything).Return(&net.OpError{Op: "dial", Err: errors.New("refused")})
+
+	handler := NewJobHandler(fulcrumClient, newTestRegistry(noopHandler), store, DefaultConfig(), monitor.NoopMonitor{})
+	require.NoError(t, handler.PollAndProcessJobs(context.Background()))
+	handler.Shutdown()
+
+	entries, err := store.ListUnfinished()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, EntryStateSucceeded, entries[0].State)
+	assert.NotEmpty(t, entries[0].Response)
+}
+
+func TestReportUnreported(t *testing.T) {
+	store := NewMemoryJobStore()
+	fulcrumClient := mocks.NewFulcrumClient(t)
+	fulcrumClient.EXPECT().GetPendingJobs(mock.Anything).Return([]*client.Job{
+		{ID: "job-1", Action: client.JobActionServiceCreate},
+		{ID: "job-2", Action: client.JobActionServiceCreate},
+	}, nil)
+	fulcrumClient.EXPECT().ClaimJob(mock.Anything, mock.Anything).Return(nil)
+	unavailable := &net.OpError{Op: "dial", Err: errors.New("refused")}
+	fulcrumClient.EXPECT().CompleteJob(mock.Anything, "job-1", mock.Anything).Return(unavailable).Once()
+	fulcrumClient.EXPECT().CompleteJob(mock.Anything, "job-1", mock.Anything).Return(nil).Once()
+	fulcrumClient.EXPECT().CompleteJob(mock.Anything, "job-2", mock.Anything).Return(unavailable).Times(2)
+
+	handler := NewJobHandler(fulcrumClient, newTestRegistry(noopHandler), store, DefaultConfig(), monitor.NoopMonitor{})
+	require.NoError(t, handler.PollAndProcessJobs(context.Background()))
+	handler.Shutdown()
+
+	// the first retry reports job-1, job-2 is kept for the next cycle
+	handler.ReportUnreported(context.Background())
+
+	entries, err := store.ListUnfinished()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "job-2", entries[0].Job.ID)
+	assert.Len(t, handler.unreported.results, 1)
+}
+
+func TestPollAndProcessJobs_CancelledAtShutdown(t *testing.T) {
+	blockingHandler := ActionHandlerFunc(func(ctx context.Context, job *client.Job) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	store := NewMemoryJobStore()
+	fulcrumClient := mocks.NewFulcrumClient(t)
+	fulcrumClient.EXPECT().GetPendingJobs(mock.Anything).Return([]*client.Job{{ID: "job-1", Action: client.JobActionServiceCreate}}, nil)
+	fulcrumClient.EXPECT().ClaimJob(mock.Anything, "job-1").Return(nil)
+
+	config := DefaultConfig()
+	config.ShutdownTimeout = 10 * time.Millisecond
+	observer := &recordingObserver{}
+	handler := NewJobHandler(fulcrumClient, newTestRegistry(blockingHandler), store, config, monitor.NoopMonitor{}, WithObserver(observer))
+	require.NoError(t, handler.PollAndProcessJobs(context.Background()))
+	handler.Shutdown()
+
+	entries, err := store.ListUnfinished()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, EntryStateDispatched, entries[0].State)
+	assert.Equal(t, []string{"ServiceCreate/cancelled"}, observer.jobs)
+}
+
+func TestIsRetryable(t *testing.T) {
+	assert.False(t, IsRetryable(nil))
+	assert.True(t, IsRetryable(NewRetryableError(errors.New("retry"))))
+	assert.False(t, IsRetryable(NewPermanentError(&net.OpError{Op: "dial", Err: errors.New("refused")})))
+	assert.True(t, IsRetryable(fmt.Errorf("post: %w", &net.OpError{Op: "dial", Err: errors.New("refused")})))
+	assert.True(t, IsRetryable(httpErr(http.StatusServiceUnavailable)))
+	assert.True(t, IsRetryable(httpErr(http.StatusTooManyRequests)))
+	assert.False(t, IsRetryable(httpErr(http.StatusBadRequest)))
+	assert.False(t, IsRetryable(fmt.Errorf("stopped: %w", context.Canceled)))
+	assert.False(t, IsRetryable(errors.New("unknown")))
+}
+
+func TestConfig_Backoff(t *testing.T) {
+	config := Config{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	assert.Equal(t, time.Second, config.backoff(2))
+	assert.Equal(t, 2*time.Second, config.backoff(3))
+	assert.Equal(t, 4*time.Second, config.backoff(4))
+	assert.Equal(t, 5*time.Second, config.backoff(5))
+	assert.Equal(t, 5*time.Second, config.backoff(50))
+}
+
+func TestPollAndProcessJobs_Observer(t *testing.T) {
+	fulcrumClient := mocks.NewFulcrumClient(t)
+	jobs := []*client.Job{
+		{ID: "job-1", Action: client.JobActionServiceCreate},
+		{ID: "job-2", Action: client.JobActionServiceDelete},
+		{ID: "job-3", Action: client.JobActionServiceStop},
+	}
+	fulcrumClient.EXPECT().GetPendingJobs(mock.Anything).Return(jobs, nil)
+	fulcrumClient.EXPECT().ClaimJob(mock.Anything, "job-1").Return(nil)
+	fulcrumClient.EXPECT().ClaimJob(mock.Anything, "job-2").Return(nil)
+	fulcrumClient.EXPECT().ClaimJob(mock.Anything, "job-3").Return(httpErr(http.StatusConflict))
+	fulcrumClient.EXPECT().CompleteJob(mock.Anything, "job-1", mock.Anything).Return(nil)
+	fulcrumClient.EXPECT().FailJob(mock.Anything, "job-2", mock.Anything).Return(nil)
+
+	actionHandler := ActionHandlerFunc(func(ctx context.Context, job *client.Job) (any, error) {
+		if job.Action == client.JobActionServiceDelete {
+			return nil, NewPermanentError(errors.New("not found"))
+		}
+		return nil, nil
+	})
+	observer := &recordingObserver{}
+	handler := NewJobHandler(fulcrumClient, newTestRegistry(actionHandler), NewMemoryJobStore(), DefaultConfig(),
+		monitor.NoopMonitor{}, WithObserver(observer))
+
+	require.NoError(t, handler.PollAndProcessJobs(context.Background()))
+	handler.Shutdown()
+
+	assert.ElementsMatch(t, []string{"ServiceCreate/succeeded", "ServiceDelete/failed", "ServiceStop/conflict"}, observer.jobs)
+	assert.Equal(t, 2, observer.timed)
+}
+
+func TestPollAndProcessJobs_Spans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	fulcrumClient := mocks.NewFulcrumClient(t)
+	jobs := []*client.Job{
+		{ID: "job-1", Action: client.JobActionServiceCreate},
+		{ID: "job-2", Action: client.JobActionServiceStop},
+	}
+	jobs[0].Service.ID = "service-1"
+	fulcrumClient.EXPECT().GetPendingJobs(mock.Anything).Return(jobs, nil)
+	fulcrumClient.EXPECT().ClaimJob(mock.Anything, "job-1").Return(nil)
+	fulcrumClient.EXPECT().ClaimJob(mock.Anything, "job-2").Return(httpErr(http.StatusConflict))
+	fulcrumClient.EXPECT().CompleteJob(mock.Anything, "job-1", mock.Anything).Return(nil)
+
+	actionHandler := ActionHandlerFunc(func(ctx context.Context, job *client.Job) (any, error) {
+		trace.SpanFromContext(ctx).SetAttributes(DeploymentIDAttribute.String("deployment-1"))
+		return nil, nil
+	})
+	handler := NewJobHandler(fulcrumClient, newTestRegistry(actionHandler), NewMemoryJobStore(), DefaultConfig(), monitor.NoopMonitor{})
+
+	require.NoError(t, handler.PollAndProcessJobs(context.Background()))
+	handler.Shutdown()
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		key := span.Name()
+		for _, kv := range span.Attributes() {
+			if kv.Key == JobIDAttribute {
+				key = kv.Value.AsString()
+			}
+		}
+		spans[key] = span
+	}
+	require.Len(t, spans, 3)
+	poll := spans["job.poll"]
+	require.NotNil(t, poll)
+
+	created := spans["job-1"]
+	require.NotNil(t, created)
+	assert.Equal(t, "job.process", created.Name())
+	assert.Equal(t, poll.SpanContext().SpanID(), created.Parent().SpanID())
+	assert.Contains(t, created.Attributes(), JobActionAttribute.String("ServiceCreate"))
+	assert.Contains(t, created.Attributes(), ServiceIDAttribute.String("service-1"))
+	assert.Contains(t, created.Attributes(), DeploymentIDAttribute.String("deployment-1"))
+	assert.Contains(t, created.Attributes(), OutcomeAttribute.String(string(OutcomeSucceeded)))
+
+	claimed := spans["job-2"]
+	require.NotNil(t, claimed)
+	assert.Contains(t, claimed.Attributes(), OutcomeAttribute.String(string(OutcomeConflict)))
+}
+
+func TestLiveness(t *testing.T) {
+	liveness := NewLiveness(time.Minute, 10*time.Minute)
+	start := time.Now()
+	assert.NoError(t, liveness.Check(start.Add(time.Hour)), "a loop that has not started is alive")
+
+	liveness.beginCycle(start)
+	assert.NoError(t, liveness.Check(start.Add(5*time.Minute)), "a long cycle is alive")
+	assert.ErrorContains(t, liveness.Check(start.Add(11*time.Minute)), "poll cycle running")
+
+	liveness.endCycle(start.Add(time.Minute))
+	assert.NoError(t, liveness.Check(start.Add(90*time.Second)))
+	assert.ErrorContains(t, liveness.Check(start.Add(3*time.Minute)), "has not ticked")
+}
+
+func TestLiveness_WaitingForWorker(t *testing.T) {
+	release := make(chan struct{})
+	blockingHandler := ActionHandlerFunc(func(ctx context.Context, job *client.Job) (any, error) {
+		<-release
+		return nil, nil
+	})
+	fulcrumClient := mocks.NewFulcrumClient(t)
+	jobs := []*client.Job{
+		{ID: "job-1", Action: client.JobActionServiceCreate},
+		{ID: "job-2", Action: client.JobActionServiceCreate},
+	}
+	fulcrumClient.EXPECT().GetPendingJobs(mock.Anything).Return(jobs, nil)
+	fulcrumClient.EXPECT().ClaimJob(mock.Anything, mock.Anything).Return(nil).Times(2)
+	fulcrumClient.EXPECT().CompleteJob(mock.Anything, mock.Anything, mock.Anything).Return(nil).Times(2)
+
+	config := DefaultConfig()
+	config.Workers = 1
+	liveness := NewLiveness(time.Minute, 10*time.Millisecond)
+	handler := NewJobHandler(fulcrumClient, newTestRegistry(blockingHandler), NewMemoryJobStore(), config,
+		monitor.NoopMonitor{}, WithLiveness(liveness))
+
+	liveness.beginCycle(time.Now())
+	done := make(chan error)
+	go func() {
+		done <- handler.PollAndProcessJobs(context.Background())
+	}()
+
+	// the cycle waits for the only worker, which is busy with the first job
+	time.Sleep(50 * time.Millisecond)
+	assert.NoError(t, liveness.Check(time.Now()))
+
+	close(release)
+	require.NoError(t, <-done)
+	handler.Shutdown()
+}
+
+func httpErr(status int) error {
+	return &client.HTTPError{Method: http.MethodPost, URL: "http://fulcrum", StatusCode: status, Retryable: status >= 500 || status == http.StatusTooManyRequests}
+}
+
+func newRetryConfig() Config {
+	config := DefaultConfig()
+	config.MaxAttempts = 3
+	config.InitialBackoff = time.Millisecond
+	config.MaxBackoff = time.Millisecond
+	return config
+}
+
+var noopHandler = ActionHandlerFunc(func(ctx context.Context, job *client.Job) (any, error) {
+	return nil, nil
+})
+
+func newTestRegistry(handler ActionHandler) *ActionRegistry {
+	registry := NewActionRegistry()
+	for _, action := range client.JobActions {
+		registry.Register(action, handler)
+	}
+	return registry
+}
+
+type recordingObserver struct {
+	mu    sync.Mutex
+	jobs  []string
+	timed int
+}
+
+func (o *recordingObserver) ObserveJob(action client.JobAction, outcome Outcome, duration time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.jobs = append(o.jobs, fmt.Sprintf("%s/%s", action, outcome))
+	if duration > 0 {
+		o.timed++
+	}
+}
+
+func (o *recordingObserver) ObservePoll(time.Duration) {}
@@ -0,0 +1,92 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package job
+
+import (
+	"fmt"
+	"github.com/metaform/connector-fabric-manager/common/system"
+	"sync"
+	"time"
+)
+
+// LivenessKey resolves the *Liveness of the job poll loop
+const LivenessKey system.ServiceType = "job:Liveness"
+
+// Liveness tracks the progress of the job poll loop. The loop is considered stuck if it has not ticked within the
+// maximum idle time, or if a poll cycle runs longer than the maximum cycle time.
+//
+// A poll cycle waits for a free worker before claiming a job. Jobs may run for a long time, bounded by their own
+// timeouts, so the time spent waiting for a worker is not counted and liveness does not depend on worker saturation.
+type Liveness struct {
+	maxIdle    time.Duration
+	maxCycle   time.Duration
+	mu         sync.Mutex
+	lastTick   time.Time // zero until the loop starts
+	cycleStart time.Time // zero while the loop waits for the next tick
+	waiting    bool      // true while the cycle waits for a free worker
+}
+
+// NewLiveness creates a tracker for a poll loop that ticks at least every maxIdle and completes a cycle within maxCycle
+func NewLiveness(maxIdle time.Duration, maxCycle time.Duration) *Liveness {
+	return &Liveness{maxIdle: maxIdle, maxCycle: maxCycle}
+}
+
+// beginCycle records the start of a poll cycle
+func (l *Liveness) beginCycle(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lastTick = now
+	l.cycleStart = now
+}
+
+// endCycle records the completion of a poll cycle
+func (l *Liveness) endCycle(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lastTick = now
+	l.cycleStart = time.Time{}
+}
+
+// beginWait records that the current cycle waits for a free worker
+func (l *Liveness) beginWait() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.waiting = true
+}
+
+// endWait records that the current cycle obtained a worker or stopped waiting, restarting the cycle time
+func (l *Liveness) endWait(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.waiting = false
+	if !l.cycleStart.IsZero() {
+		l.cycleStart = now
+	}
+}
+
+// Check returns an error if the poll loop is stuck. A loop that has not started yet is considered alive.
+func (l *Liveness) Check(now time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	switch {
+	case l.lastTick.IsZero(), l.waiting:
+		return nil
+	case !l.cycleStart.IsZero():
+		if running := now.Sub(l.cycleStart); running > l.maxCycle {
+			return fmt.Errorf("poll cycle running for %s, exceeding %s", running.Round(time.Second), l.maxCycle)
+		}
+	case now.Sub(l.lastTick) > l.maxIdle:
+		return fmt.Errorf("poll loop has not ticked for %s, exceeding %s", now.Sub(l.lastTick).Round(time.Second), l.maxIdle)
+	}
+	return nil
+}
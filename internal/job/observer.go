@@ -0,0 +1,71 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package job
+
+import (
+	"github.com/metaform/cfm-fulcrum/internal/client"
+	"github.com/metaform/connector-fabric-manager/common/system"
+	"time"
+)
+
+// ObserverKey resolves the Observer notified of job processing events
+const ObserverKey system.ServiceType = "job:Observer"
+
+// Outcome is the result of handling a pending job
+type Outcome string
+
+const (
+	// OutcomeSucceeded indicates the job was processed and its completion reported
+	OutcomeSucceeded Outcome = "succeeded"
+	// OutcomeFailed indicates the job was claimed but processing failed
+	OutcomeFailed Outcome = "failed"
+	// OutcomeConflict indicates the job was claimed by another agent
+	OutcomeConflict Outcome = "conflict"
+	// OutcomeClaimFailed indicates the job could not be claimed
+	OutcomeClaimFailed Outcome = "claim_failed"
+	// OutcomeCancelled indicates processing was cancelled at shutdown and the job is left for recovery
+	OutcomeCancelled Outcome = "cancelled"
+)
+
+// Observer receives job processing events, e.g. to record metrics
+type Observer interface {
+	// ObserveJob is called once per handled job. The duration is measured from claiming the job to reporting its
+	// result, and is zero if the job was not claimed or the claim time is unknown.
+	ObserveJob(action client.JobAction, outcome Outcome, duration time.Duration)
+
+	// ObservePoll is called when a poll cycle starts with the delay past its scheduled time
+	ObservePoll(lag time.Duration)
+}
+
+// JobHandlerOption configures a JobHandler
+type JobHandlerOption func(*JobHandler)
+
+// WithObserver notifies the observer of job processing events
+func WithObserver(observer Observer) JobHandlerOption {
+	return func(h *JobHandler) {
+		h.observer = observer
+	}
+}
+
+// WithLiveness excludes the time spent waiting for a free worker from the poll cycle time checked by the tracker
+func WithLiveness(liveness *Liveness) JobHandlerOption {
+	return func(h *JobHandler) {
+		h.liveness = liveness
+	}
+}
+
+type noopObserver struct{}
+
+func (noopObserver) ObserveJob(client.JobAction, Outcome, time.Duration) {}
+
+func (noopObserver) ObservePoll(time.Duration) {}
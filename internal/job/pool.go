@@ -0,0 +1,107 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package job
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// workerPool runs tasks on a bounded number of concurrent workers.
+//
+// A worker slot must be acquired before a task is submitted. This allows callers to wait for capacity before
+// performing work that should only happen when a task can run immediately, such as claiming a job.
+type workerPool struct {
+	slots   chan struct{}
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	stopped bool
+	closed  chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+func newWorkerPool(size int) *workerPool {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &workerPool{
+		slots:  make(chan struct{}, size),
+		closed: make(chan struct{}),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// acquire blocks until a worker slot is available. It returns false if the pool is shut down while waiting.
+// A successful acquire must be followed by either submit or release.
+func (p *workerPool) acquire() bool {
+	select {
+	case p.slots <- struct{}{}:
+	case <-p.closed:
+		return false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped {
+		<-p.slots
+		return false
+	}
+	p.wg.Add(1)
+	return true
+}
+
+// release returns a previously acquired worker slot without running a task.
+func (p *workerPool) release() {
+	<-p.slots
+	p.wg.Done()
+}
+
+// submit runs the task on an acquired worker slot and releases the slot when the task returns. The task context is
+// cancelled if the pool shutdown times out before the task completes.
+func (p *workerPool) submit(task func(ctx context.Context)) {
+	go func() {
+		defer p.release()
+		task(p.ctx)
+	}()
+}
+
+// shutdown stops accepting tasks and waits for in-flight tasks to complete. If the timeout elapses first, the
+// context of the in-flight tasks is cancelled and shutdown waits for them to return. It returns false if tasks had to
+// be cancelled.
+func (p *workerPool) shutdown(timeout time.Duration) bool {
+	p.mu.Lock()
+	if p.stopped {
+		p.mu.Unlock()
+		return true
+	}
+	p.stopped = true
+	close(p.closed)
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		p.cancel()
+		return true
+	case <-time.After(timeout):
+		p.cancel()
+		<-done
+		return false
+	}
+}
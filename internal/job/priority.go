@@ -0,0 +1,51 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package job
+
+import (
+	"github.com/metaform/cfm-fulcrum/internal/client"
+	"sort"
+	"time"
+)
+
+// orderJobs sorts jobs so that the most urgent job comes first. Jobs are ordered by effective priority (higher first),
+// then by creation time (older first). Jobs without a creation time come after jobs with one. Jobs that cannot be
+// distinguished keep the order returned by Fulcrum Core.
+//
+// If aging is greater than zero, a job's effective priority is raised by one for every aging interval it has been
+// pending. This guarantees that low-priority jobs are eventually selected even when higher-priority jobs keep arriving.
+func orderJobs(jobs []*client.Job, aging time.Duration, now time.Time) {
+	sort.SliceStable(jobs, func(i, j int) bool {
+		pi, pj := effectivePriority(jobs[i], aging, now), effectivePriority(jobs[j], aging, now)
+		if pi != pj {
+			return pi > pj
+		}
+		ci, cj := jobs[i].CreatedAt, jobs[j].CreatedAt
+		if ci.IsZero() != cj.IsZero() {
+			return !ci.IsZero()
+		}
+		return ci.Before(cj)
+	})
+}
+
+// effectivePriority returns the job priority raised by the time the job has been waiting
+func effectivePriority(job *client.Job, aging time.Duration, now time.Time) int {
+	if aging <= 0 || job.CreatedAt.IsZero() {
+		return job.Priority
+	}
+	waiting := now.Sub(job.CreatedAt)
+	if waiting <= 0 {
+		return job.Priority
+	}
+	return job.Priority + int(waiting/aging)
+}
@@ -0,0 +1,68 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package job
+
+import (
+	"context"
+	"github.com/metaform/cfm-fulcrum/internal/client"
+	"github.com/metaform/connector-fabric-manager/common/system"
+	"sync"
+)
+
+const (
+	ActionRegistryKey system.ServiceType = "job:ActionRegistry"
+)
+
+// ActionHandler processes a claimed job for a specific client.JobAction.
+//
+// The returned value is sent to Fulcrum Core as the job completion payload. Returning an error fails the job.
+type ActionHandler interface {
+	Handle(ctx context.Context, job *client.Job) (any, error)
+}
+
+// ActionHandlerFunc adapts a function to the ActionHandler interface
+type ActionHandlerFunc func(ctx context.Context, job *client.Job) (any, error)
+
+func (f ActionHandlerFunc) Handle(ctx context.Context, job *client.Job) (any, error) {
+	return f(ctx, job)
+}
+
+// ActionRegistry holds the ActionHandler for each job action.
+//
+// The registry is provided by the JobServiceAssembly. Other assemblies contribute handlers by requiring
+// ActionRegistryKey and registering them during Init.
+type ActionRegistry struct {
+	mu       sync.RWMutex
+	handlers map[client.JobAction]ActionHandler
+}
+
+func NewActionRegistry() *ActionRegistry {
+	return &ActionRegistry{
+		handlers: make(map[client.JobAction]ActionHandler),
+	}
+}
+
+// Register sets the handler for the given action, replacing a previously registered handler
+func (r *ActionRegistry) Register(action client.JobAction, handler ActionHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[action] = handler
+}
+
+// Resolve returns the handler for the given action and a boolean indicating whether one is registered
+func (r *ActionRegistry) Resolve(action client.JobAction) (ActionHandler, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	handler, found := r.handlers[action]
+	return handler, found
+}
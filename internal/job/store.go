@@ -0,0 +1,109 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package job
+
+import (
+	"encoding/json"
+	"github.com/metaform/cfm-fulcrum/internal/client"
+	"sort"
+	"sync"
+	"time"
+)
+
+// EntryState is the processing state of a journaled job
+type EntryState string
+
+const (
+	// EntryStateClaimed indicates the job was claimed in Fulcrum Core but processing has not started
+	EntryStateClaimed EntryState = "Claimed"
+	// EntryStateDispatched indicates the job was handed to its ActionHandler
+	EntryStateDispatched EntryState = "Dispatched"
+	// EntryStateSucceeded indicates the job was processed but completion has not been reported to Fulcrum Core
+	EntryStateSucceeded EntryState = "Succeeded"
+	// EntryStateFailed indicates the job failed but the failure has not been reported to Fulcrum Core
+	EntryStateFailed EntryState = "Failed"
+)
+
+// JournalEntry records the progress of a claimed job so that it can be finished after an agent restart
+type JournalEntry struct {
+	Job       *client.Job     `json:"job"`
+	State     EntryState      `json:"state"`
+	Response  json.RawMessage `json:"response,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
+
+// JobStore journals job state transitions between claiming a job and reporting its result to Fulcrum Core.
+//
+// Entries are removed once the result has been reported. Any entry left in the store when the agent starts belongs to
+// a job the agent was processing when it stopped.
+type JobStore interface {
+	// Save creates or replaces the entry for the entry's job
+	Save(entry *JournalEntry) error
+
+	// Delete removes the entry for a job. Deleting an entry that does not exist is not an error.
+	Delete(jobID string) error
+
+	// ListUnfinished returns all entries in the store ordered by their last update
+	ListUnfinished() ([]*JournalEntry, error)
+
+	// Close releases resources held by the store
+	Close() error
+}
+
+// MemoryJobStore is a JobStore that keeps entries in memory. Entries do not survive a restart of the agent.
+type MemoryJobStore struct {
+	mu      sync.RWMutex
+	entries map[string]*JournalEntry
+}
+
+func NewMemoryJobStore() *MemoryJobStore {
+	return &MemoryJobStore{entries: make(map[string]*JournalEntry)}
+}
+
+func (s *MemoryJobStore) Save(entry *JournalEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copied := *entry
+	s.entries[entry.Job.ID] = &copied
+	return nil
+}
+
+func (s *MemoryJobStore) Delete(jobID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, jobID)
+	return nil
+}
+
+func (s *MemoryJobStore) ListUnfinished() ([]*JournalEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return sortedEntries(s.entries), nil
+}
+
+func (s *MemoryJobStore) Close() error {
+	return nil
+}
+
+func sortedEntries(entries map[string]*JournalEntry) []*JournalEntry {
+	result := make([]*JournalEntry, 0, len(entries))
+	for _, entry := range entries {
+		copied := *entry
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].UpdatedAt.Before(result[j].UpdatedAt)
+	})
+	return result
+}
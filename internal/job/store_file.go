@@ -0,0 +1,205 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package job
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// compactThreshold is the number of records appended to the journal file after which it is rewritten
+const compactThreshold = 1000
+
+// journalRecord is a line in the journal file. A record either saves an entry or deletes the entry of a job.
+type journalRecord struct {
+	Entry   *JournalEntry `json:"entry,omitempty"`
+	Deleted string        `json:"deleted,omitempty"`
+}
+
+// FileJobStore is a JobStore that appends state transitions to a journal file.
+//
+// Every record is synced to disk before Save or Delete returns. When the store is opened, the journal is replayed to
+// restore unfinished entries and rewritten to contain only those entries. The journal is also rewritten once
+// compactThreshold records have been appended.
+type FileJobStore struct {
+	mu       sync.Mutex
+	path     string
+	file     *os.File
+	entries  map[string]*JournalEntry
+	appended int
+}
+
+// NewFileJobStore opens the journal at the given path, creating it if it does not exist
+func NewFileJobStore(path string) (*FileJobStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create job journal directory: %w", err)
+	}
+	store := &FileJobStore{
+		path:    path,
+		entries: make(map[string]*JournalEntry),
+	}
+	if err := store.load(); err != nil {
+		return nil, err
+	}
+	if err := store.compact(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *FileJobStore) Save(entry *JournalEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copied := *entry
+	if err := s.append(journalRecord{Entry: &copied}); err != nil {
+		return err
+	}
+	s.entries[entry.Job.ID] = &copied
+	return nil
+}
+
+func (s *FileJobStore) Delete(jobID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, found := s.entries[jobID]; !found {
+		return nil
+	}
+	if err := s.append(journalRecord{Deleted: jobID}); err != nil {
+		return err
+	}
+	delete(s.entries, jobID)
+	return nil
+}
+
+func (s *FileJobStore) ListUnfinished() ([]*JournalEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return sortedEntries(s.entries), nil
+}
+
+func (s *FileJobStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// load replays the journal file. A truncated last line, which can be left by a crash during a write, is ignored. Any
+// other unreadable record fails loading, since skipping it could lose a journaled claim or result.
+func (s *FileJobStore) load() error {
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to open job journal: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	var corrupt error
+	for line := 1; scanner.Scan(); line++ {
+		if corrupt != nil {
+			return fmt.Errorf("failed to read job journal %s: %w", s.path, corrupt)
+		}
+		var record journalRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			corrupt = fmt.Errorf("corrupt record at line %d: %w", line, err)
+			continue
+		}
+		switch {
+		case record.Entry != nil && record.Entry.Job != nil:
+			s.entries[record.Entry.Job.ID] = record.Entry
+		case record.Deleted != "":
+			delete(s.entries, record.Deleted)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read job journal: %w", err)
+	}
+	return nil
+}
+
+func (s *FileJobStore) append(record journalRecord) error {
+	if s.file == nil {
+		return fmt.Errorf("job journal is closed")
+	}
+	if s.appended >= compactThreshold {
+		if err := s.compact(); err != nil {
+			return err
+		}
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job journal record: %w", err)
+	}
+	if _, err := s.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write job journal: %w", err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync job journal: %w", err)
+	}
+	s.appended++
+	return nil
+}
+
+// compact atomically replaces the journal with a file containing only the current entries
+func (s *FileJobStore) compact() error {
+	tmpPath := s.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create job journal: %w", err)
+	}
+	writer := bufio.NewWriter(tmp)
+	for _, entry := range sortedEntries(s.entries) {
+		data, err := json.Marshal(journalRecord{Entry: entry})
+		if err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to marshal job journal record: %w", err)
+		}
+		writer.Write(append(data, '\n'))
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write job journal: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync job journal: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close job journal: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("failed to replace job journal: %w", err)
+	}
+
+	if s.file != nil {
+		s.file.Close()
+	}
+	s.file, err = os.OpenFile(s.path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		s.file = nil
+		return fmt.Errorf("failed to open job journal: %w", err)
+	}
+	s.appended = 0
+	return nil
+}
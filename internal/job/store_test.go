@@ -0,0 +1,95 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package job
+
+import (
+	"github.com/metaform/cfm-fulcrum/internal/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileJobStore_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal", "jobs.jsonl")
+
+	store, err := NewFileJobStore(path)
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	require.NoError(t, store.Save(&JournalEntry{Job: &client.Job{ID: "job-1"}, State: EntryStateClaimed, UpdatedAt: now}))
+	require.NoError(t, store.Save(&JournalEntry{Job: &client.Job{ID: "job-2"}, State: EntryStateClaimed, UpdatedAt: now.Add(time.Second)}))
+	require.NoError(t, store.Save(&JournalEntry{Job: &client.Job{ID: "job-1"}, State: EntryStateFailed, Error: "boom", UpdatedAt: now.Add(2 * time.Second)}))
+	require.NoError(t, store.Save(&JournalEntry{Job: &client.Job{ID: "job-3"}, State: EntryStateDispatched, UpdatedAt: now.Add(3 * time.Second)}))
+	require.NoError(t, store.Delete("job-2"))
+	require.NoError(t, store.Close())
+
+	// simulate a crash during a write
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"entry":{"job":{"id":"job-4"`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	store, err = NewFileJobStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	entries, err := store.ListUnfinished()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "job-1", entries[0].Job.ID)
+	assert.Equal(t, EntryStateFailed, entries[0].State)
+	assert.Equal(t, "boom", entries[0].Error)
+	assert.Equal(t, "job-3", entries[1].Job.ID)
+	assert.Equal(t, EntryStateDispatched, entries[1].State)
+}
+
+func TestFileJobStore_CorruptRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.jsonl")
+	journal := `{"entry":{"job":{"id":"job-1"},"state":"claimed"}}
+{"entry":{"job":{"id":"job-2"
+{"entry":{"job":{"id":"job-3"},"state":"claimed"}}
+`
+	require.NoError(t, os.WriteFile(path, []byte(journal), 0o600))
+
+	_, err := NewFileJobStore(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 2")
+
+	// the journal is left untouched for inspection
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, journal, string(data))
+}
+
+func TestFileJobStore_Compact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.jsonl")
+	store, err := NewFileJobStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	for i := 0; i < compactThreshold+10; i++ {
+		require.NoError(t, store.Save(&JournalEntry{Job: &client.Job{ID: "job-1"}, State: EntryStateClaimed}))
+	}
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Less(t, len(data), 20*200)
+
+	entries, err := store.ListUnfinished()
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
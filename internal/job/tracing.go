@@ -0,0 +1,39 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package job
+
+import (
+	"github.com/metaform/cfm-fulcrum/internal/client"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var tracer = otel.Tracer("github.com/metaform/cfm-fulcrum/internal/job")
+
+// Span attributes identifying a job and the resources it affects. Action handlers add the IDs of the resources they
+// create, such as the deployment ID, to the span of the context passed to them.
+const (
+	JobIDAttribute        = attribute.Key("cfm.job.id")
+	JobActionAttribute    = attribute.Key("cfm.job.action")
+	ServiceIDAttribute    = attribute.Key("cfm.service.id")
+	DeploymentIDAttribute = attribute.Key("cfm.deployment.id")
+	OutcomeAttribute      = attribute.Key("cfm.job.outcome")
+)
+
+func jobAttributes(job *client.Job) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		JobIDAttribute.String(job.ID),
+		JobActionAttribute.String(string(job.Action)),
+		ServiceIDAttribute.String(job.Service.ID),
+	}
+}
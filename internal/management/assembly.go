@@ -17,12 +17,31 @@ import (
 	"fmt"
 	"github.com/go-chi/chi/v5"
 	"github.com/metaform/cfm-fulcrum/internal/client"
+	"github.com/metaform/cfm-fulcrum/internal/deployment"
 	"github.com/metaform/connector-fabric-manager/assembly/httpclient"
 	"github.com/metaform/connector-fabric-manager/assembly/routing"
 	"github.com/metaform/connector-fabric-manager/common/system"
 	"net/http"
 )
 
+const (
+	authTypeKey          = "management.auth.type"
+	apiKeysReadKey       = "management.auth.api_keys.read"
+	apiKeysAdminKey      = "management.auth.api_keys.admin"
+	mtlsReadSubjectsKey  = "management.auth.mtls.read_subjects"
+	mtlsAdminSubjectsKey = "management.auth.mtls.admin_subjects"
+	mtlsSubjectHeaderKey = "management.auth.mtls.subject_header"
+	mtlsTrustedProxyKey  = "management.auth.mtls.trusted_proxies"
+	jwtJWKSFileKey       = "management.auth.jwt.jwks_file"
+	jwtIssuerKey         = "management.auth.jwt.issuer"
+	jwtAudienceKey       = "management.auth.jwt.audience"
+	jwtReadScopeKey      = "management.auth.jwt.read_scope"
+	jwtAdminScopeKey     = "management.auth.jwt.admin_scope"
+
+	defaultReadScope  = "cfm-agent:read"
+	defaultAdminScope = "cfm-agent:admin"
+)
+
 type ManagementServiceAssembly struct {
 	system.DefaultServiceAssembly
 }
@@ -32,49 +51,89 @@ func (a *ManagementServiceAssembly) Name() string {
 }
 
 func (d *ManagementServiceAssembly) Requires() []system.ServiceType {
-	return []system.ServiceType{routing.RouterKey, httpclient.HttpClientKey}
+	return []system.ServiceType{routing.RouterKey, httpclient.HttpClientKey, deployment.TrackerKey}
 }
 
 func (a *ManagementServiceAssembly) Init(context *system.InitContext) error {
 	router := context.Registry.Resolve(routing.RouterKey).(chi.Router)
 	fulcrumClient := context.Registry.Resolve(client.FulcrumClientKey).(client.FulcrumClient)
 
-	router.Get("/ping", func(w http.ResponseWriter, r *http.Request) {
-		response := response{Message: "OK"}
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
-	})
+	auth, err := loadAuthenticator(context)
+	if err != nil {
+		return err
+	}
 
-	router.Post("/fulcrum-token", func(w http.ResponseWriter, r *http.Request) {
-		var result map[string]any
-		if err := json.NewDecoder(r.Body).Decode(&result); err != nil {
-			http.Error(w, fmt.Sprintf("failed to unmarshal JSON: %v", err), http.StatusBadRequest)
-			return
-		}
+	tokens := &tokenHandler{fulcrumClient: fulcrumClient, monitor: context.LogMonitor}
+	if tracker, found := context.Registry.ResolveOptional(client.TokenTrackerKey); found {
+		tokens.tracker = tracker.(*client.TokenTracker)
+	}
 
-		// Extract token from the request body
-		token, ok := result["token"].(string)
-		if !ok {
-			http.Error(w, "token field is required and must be a string", http.StatusBadRequest)
-			return
-		}
+	router.Group(func(r chi.Router) {
+		r.Use(requireScope(auth, ScopeRead, context.LogMonitor))
+		r.Get("/ping", func(w http.ResponseWriter, r *http.Request) {
+			response := response{Message: "OK"}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(response)
+		})
+		r.Get("/fulcrum-token", tokens.status)
+	})
 
-		if err := fulcrumClient.UpdateToken(token); err != nil {
-			context.LogMonitor.Severef("error updating token: %w", err)
-			http.Error(w, fmt.Sprintf("error updating token: %v", err), http.StatusInternalServerError)
-			return
-		}
+	deployments := &deploymentHandler{
+		tracker: context.Registry.Resolve(deployment.TrackerKey).(*deployment.Tracker),
+		monitor: context.LogMonitor,
+	}
 
-		w.WriteHeader(http.StatusCreated)
-		w.Header().Set("Content-Type", "application/json")
-		response := response{Message: "OK"}
-		json.NewEncoder(w).Encode(response)
+	router.Group(func(r chi.Router) {
+		r.Use(requireScope(auth, ScopeAdmin, context.LogMonitor))
+		r.Post("/fulcrum-token", tokens.update)
+		r.Post("/deployments/{id}/status", deployments.status)
 	})
 
 	return nil
 
 }
 
+// loadAuthenticator creates the authenticator selected by the management.auth.type setting. It returns nil if
+// authentication is disabled.
+func loadAuthenticator(context *system.InitContext) (requestAuthenticator, error) {
+	authType := context.GetConfigStrOrDefault(authTypeKey, AuthTypeNone)
+	var auth requestAuthenticator
+	var err error
+	switch authType {
+	case AuthTypeNone:
+		context.LogMonitor.Warnf("Management API authentication is disabled, set %s to protect it", authTypeKey)
+		return nil, nil
+	case AuthTypeAPIKey:
+		auth, err = newAPIKeyAuthenticator(
+			context.Config.GetStringSlice(apiKeysReadKey),
+			context.Config.GetStringSlice(apiKeysAdminKey))
+	case AuthTypeMTLS:
+		if context.Config.GetString(mtlsSubjectHeaderKey) == "" {
+			context.LogMonitor.Warnf("%s is not set, but the management API serves plain HTTP, so no client certificate "+
+				"subjects can be verified", mtlsSubjectHeaderKey)
+		}
+		auth, err = newSubjectAuthenticator(
+			context.Config.GetStringSlice(mtlsReadSubjectsKey),
+			context.Config.GetStringSlice(mtlsAdminSubjectsKey),
+			context.Config.GetString(mtlsSubjectHeaderKey),
+			context.Config.GetStringSlice(mtlsTrustedProxyKey))
+	case AuthTypeJWT:
+		auth, err = newJWTAuthenticator(JWTConfig{
+			JWKSFile:   context.Config.GetString(jwtJWKSFileKey),
+			Issuer:     context.Config.GetString(jwtIssuerKey),
+			Audience:   context.Config.GetString(jwtAudienceKey),
+			ReadScope:  context.GetConfigStrOrDefault(jwtReadScopeKey, defaultReadScope),
+			AdminScope: context.GetConfigStrOrDefault(jwtAdminScopeKey, defaultAdminScope),
+		})
+	default:
+		return nil, fmt.Errorf("invalid %s: unsupported authentication type %q", authTypeKey, authType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid management.auth configuration: %w", err)
+	}
+	return auth, nil
+}
+
 type response struct {
 	Message string `json:"message"`
 }
@@ -0,0 +1,230 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package management
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"github.com/metaform/connector-fabric-manager/common/monitor"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+// Scope is the level of access granted to a management API caller. Admin includes read access.
+type Scope int
+
+const (
+	ScopeNone Scope = iota
+	ScopeRead
+	ScopeAdmin
+)
+
+func (s Scope) String() string {
+	switch s {
+	case ScopeRead:
+		return "read"
+	case ScopeAdmin:
+		return "admin"
+	}
+	return "none"
+}
+
+// Supported values of the management.auth.type setting
+const (
+	AuthTypeNone   = "none"
+	AuthTypeAPIKey = "apikey"
+	AuthTypeMTLS   = "mtls"
+	AuthTypeJWT    = "jwt"
+)
+
+// apiKeyHeader carries the API key of a management API caller
+const apiKeyHeader = "X-Api-Key"
+
+// errUnauthenticated is returned when a request carries no credentials
+var errUnauthenticated = errors.New("no credentials provided")
+
+// requestAuthenticator determines the scope granted to a management API request. An error means the caller could not
+// be authenticated.
+type requestAuthenticator interface {
+	authenticate(r *http.Request) (Scope, error)
+}
+
+// requireScope returns middleware that rejects requests without the required scope. A nil authenticator disables
+// authentication.
+func requireScope(auth requestAuthenticator, required Scope, logMonitor monitor.LogMonitor) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if auth == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scope, err := auth.authenticate(r)
+			if err != nil {
+				if !errors.Is(err, errUnauthenticated) {
+					logMonitor.Infof("Rejected management request %s %s: %v", r.Method, r.URL.Path, err)
+				}
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			if scope < required {
+				logMonitor.Infof("Rejected management request %s %s: %s scope required, caller has %s",
+					r.Method, r.URL.Path, required, scope)
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// apiKeyAuthenticator grants the scope associated with the API key in the X-Api-Key header
+type apiKeyAuthenticator struct {
+	keys map[string]Scope
+}
+
+func newAPIKeyAuthenticator(readKeys []string, adminKeys []string) (*apiKeyAuthenticator, error) {
+	keys := make(map[string]Scope)
+	for _, key := range readKeys {
+		keys[key] = ScopeRead
+	}
+	for _, key := range adminKeys {
+		keys[key] = ScopeAdmin
+	}
+	delete(keys, "")
+	if len(keys) == 0 {
+		return nil, errors.New("at least one API key is required")
+	}
+	return &apiKeyAuthenticator{keys: keys}, nil
+}
+
+func (a *apiKeyAuthenticator) authenticate(r *http.Request) (Scope, error) {
+	provided := r.Header.Get(apiKeyHeader)
+	if provided == "" {
+		return ScopeNone, errUnauthenticated
+	}
+	// compare against every key so timing does not reveal which keys exist
+	granted := ScopeNone
+	for key, scope := range a.keys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(provided)) == 1 && scope > granted {
+			granted = scope
+		}
+	}
+	if granted == ScopeNone {
+		return ScopeNone, errors.New("unknown API key")
+	}
+	return granted, nil
+}
+
+// subjectAuthenticator grants the scope associated with the subject of a verified client certificate. The certificate
+// is taken from the TLS connection, or from a header set by a TLS-terminating proxy that verifies client certificates.
+// Subjects are matched either by common name or by their full distinguished name.
+//
+// The agent serves plain HTTP, so in practice the subject comes from the proxy header. Any client can set that header,
+// so it is only accepted from the trusted proxy addresses. The proxy must require client certificates and overwrite
+// the header on every request it forwards.
+type subjectAuthenticator struct {
+	subjects       map[string]Scope
+	subjectHeader  string
+	trustedProxies []netip.Prefix
+}
+
+func newSubjectAuthenticator(
+	readSubjects []string,
+	adminSubjects []string,
+	subjectHeader string,
+	trustedProxies []string) (*subjectAuthenticator, error) {
+	subjects := make(map[string]Scope)
+	for _, subject := range readSubjects {
+		subjects[subject] = ScopeRead
+	}
+	for _, subject := range adminSubjects {
+		subjects[subject] = ScopeAdmin
+	}
+	delete(subjects, "")
+	if len(subjects) == 0 {
+		return nil, errors.New("at least one client certificate subject is required")
+	}
+	a := &subjectAuthenticator{subjects: subjects, subjectHeader: subjectHeader}
+	for _, proxy := range trustedProxies {
+		prefix, err := parsePrefix(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		a.trustedProxies = append(a.trustedProxies, prefix)
+	}
+	if subjectHeader != "" && len(a.trustedProxies) == 0 {
+		return nil, errors.New("a client certificate subject header requires at least one trusted proxy")
+	}
+	return a, nil
+}
+
+func (a *subjectAuthenticator) authenticate(r *http.Request) (Scope, error) {
+	var names []string
+	switch {
+	case r.TLS != nil && len(r.TLS.VerifiedChains) > 0:
+		subject := r.TLS.VerifiedChains[0][0].Subject
+		names = []string{subject.CommonName, subject.String()}
+	case a.subjectHeader != "" && r.Header.Get(a.subjectHeader) != "":
+		if !a.fromTrustedProxy(r) {
+			return ScopeNone, fmt.Errorf("client certificate subject header sent from untrusted address %s", r.RemoteAddr)
+		}
+		subject, err := url.QueryUnescape(r.Header.Get(a.subjectHeader))
+		if err != nil {
+			return ScopeNone, fmt.Errorf("invalid client certificate subject header: %w", err)
+		}
+		names = []string{subject}
+	default:
+		return ScopeNone, errUnauthenticated
+	}
+
+	granted := ScopeNone
+	for _, name := range names {
+		if scope := a.subjects[strings.TrimSpace(name)]; scope > granted {
+			granted = scope
+		}
+	}
+	if granted == ScopeNone {
+		return ScopeNone, fmt.Errorf("client certificate subject %q is not allowed", names[len(names)-1])
+	}
+	return granted, nil
+}
+
+// fromTrustedProxy returns true if the request was sent from a trusted proxy address
+func (a *subjectAuthenticator) fromTrustedProxy(r *http.Request) bool {
+	addrPort, err := netip.ParseAddrPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	addr := addrPort.Addr().Unmap()
+	for _, prefix := range a.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePrefix parses a CIDR range or a single IP address
+func parsePrefix(value string) (netip.Prefix, error) {
+	if strings.Contains(value, "/") {
+		prefix, err := netip.ParsePrefix(value)
+		return prefix.Masked(), err
+	}
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()), nil
+}
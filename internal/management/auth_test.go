@@ -0,0 +1,209 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package management
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/metaform/connector-fabric-manager/common/monitor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRequireScope_APIKey(t *testing.T) {
+	auth, err := newAPIKeyAuthenticator([]string{"read-key"}, []string{"admin-key"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		key      string
+		required Scope
+		expected int
+	}{
+		{name: "no key", required: ScopeRead, expected: http.StatusUnauthorized},
+		{name: "unknown key", key: "other", required: ScopeRead, expected: http.StatusUnauthorized},
+		{name: "read key on read route", key: "read-key", required: ScopeRead, expected: http.StatusOK},
+		{name: "read key on admin route", key: "read-key", required: ScopeAdmin, expected: http.StatusForbidden},
+		{name: "admin key on read route", key: "admin-key", required: ScopeRead, expected: http.StatusOK},
+		{name: "admin key on admin route", key: "admin-key", required: ScopeAdmin, expected: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+			if tt.key != "" {
+				req.Header.Set(apiKeyHeader, tt.key)
+			}
+			assert.Equal(t, tt.expected, serve(auth, tt.required, req))
+		})
+	}
+}
+
+func TestRequireScope_Disabled(t *testing.T) {
+	assert.Equal(t, http.StatusOK, serve(nil, ScopeAdmin, httptest.NewRequest(http.MethodPost, "/fulcrum-token", nil)))
+}
+
+func TestSubjectAuthenticator_Header(t *testing.T) {
+	auth, err := newSubjectAuthenticator([]string{"CN=monitoring,O=Metaform"}, []string{"operator"}, "X-Client-Subject",
+		[]string{"10.0.0.0/8", "192.0.2.1"})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set("X-Client-Subject", "CN%3Dmonitoring%2CO%3DMetaform")
+	scope, err := auth.authenticate(req)
+	require.NoError(t, err)
+	assert.Equal(t, ScopeRead, scope)
+
+	req.Header.Set("X-Client-Subject", "operator")
+	scope, err = auth.authenticate(req)
+	require.NoError(t, err)
+	assert.Equal(t, ScopeAdmin, scope)
+
+	req.Header.Set("X-Client-Subject", "intruder")
+	_, err = auth.authenticate(req)
+	assert.Error(t, err)
+
+	req.Header.Set("X-Client-Subject", "operator")
+	req.RemoteAddr = "203.0.113.7:4711"
+	_, err = auth.authenticate(req)
+	assert.ErrorContains(t, err, "untrusted address")
+
+	req.RemoteAddr = "10.1.2.3:4711"
+	scope, err = auth.authenticate(req)
+	require.NoError(t, err)
+	assert.Equal(t, ScopeAdmin, scope)
+}
+
+func TestSubjectAuthenticator_HeaderRequiresTrustedProxy(t *testing.T) {
+	_, err := newSubjectAuthenticator(nil, []string{"operator"}, "X-Client-Subject", nil)
+	assert.Error(t, err)
+
+	_, err = newSubjectAuthenticator(nil, []string{"operator"}, "X-Client-Subject", []string{"not-an-address"})
+	assert.Error(t, err)
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	writeJSON(t, jwksFile, map[string]any{"keys": []map[string]any{
+		{"kty": "RSA", "kid": "rsa", "use": "sig", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": b64(ecKey.X.FillBytes(make([]byte, 32))), "y": b64(ecKey.Y.FillBytes(make([]byte, 32)))},
+	}})
+
+	auth, err := newJWTAuthenticator(JWTConfig{
+		JWKSFile:   jwksFile,
+		Issuer:     "https://issuer",
+		Audience:   "cfm-agent",
+		ReadScope:  defaultReadScope,
+		AdminScope: defaultAdminScope,
+	})
+	require.NoError(t, err)
+
+	exp := time.Now().Add(time.Hour).Unix()
+	claims := map[string]any{"iss": "https://issuer", "aud": []string{"cfm-agent"}, "exp": exp, "scope": "openid cfm-agent:admin"}
+
+	tests := []struct {
+		name     string
+		token    string
+		expected Scope
+		wantErr  bool
+	}{
+		{name: "RSA admin", token: signRS256(t, rsaKey, "rsa", claims), expected: ScopeAdmin},
+		{name: "EC read", token: signES256(t, ecKey, "ec", withClaim(claims, "scope", "cfm-agent:read")), expected: ScopeRead},
+		{name: "no scope", token: signRS256(t, rsaKey, "rsa", withClaim(claims, "scope", "openid")), expected: ScopeNone},
+		{name: "expired", token: signRS256(t, rsaKey, "rsa", withClaim(claims, "exp", time.Now().Add(-time.Hour).Unix())), wantErr: true},
+		{name: "wrong audience", token: signRS256(t, rsaKey, "rsa", withClaim(claims, "aud", "other")), wantErr: true},
+		{name: "wrong issuer", token: signRS256(t, rsaKey, "rsa", withClaim(claims, "iss", "https://other")), wantErr: true},
+		{name: "unknown key", token: signRS256(t, rsaKey, "other", claims), wantErr: true},
+		{name: "tampered", token: signRS256(t, rsaKey, "rsa", claims) + "x", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			scope, err := auth.authenticate(req)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, scope)
+		})
+	}
+}
+
+func serve(auth requestAuthenticator, required Scope, req *http.Request) int {
+	handler := requireScope(auth, required, monitor.NoopMonitor{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func withClaim(claims map[string]any, name string, value any) map[string]any {
+	result := make(map[string]any, len(claims))
+	for k, v := range claims {
+		result[k] = v
+	}
+	result[name] = value
+	return result
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	signed, digest := signingInput(t, "RS256", kid, claims)
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest)
+	require.NoError(t, err)
+	return signed + "." + b64(signature)
+}
+
+func signES256(t *testing.T, key *ecdsa.PrivateKey, kid string, claims map[string]any) string {
+	signed, digest := signingInput(t, "ES256", kid, claims)
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest)
+	require.NoError(t, err)
+	return signed + "." + b64(append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...))
+}
+
+func signingInput(t *testing.T, alg string, kid string, claims map[string]any) (string, []byte) {
+	header, err := json.Marshal(map[string]any{"alg": alg, "kid": kid, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signed := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signed))
+	return signed, digest[:]
+}
+
+func writeJSON(t *testing.T, path string, v any) {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0600))
+}
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
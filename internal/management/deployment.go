@@ -0,0 +1,50 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package management
+
+import (
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/metaform/cfm-fulcrum/internal/deployment"
+	"github.com/metaform/connector-fabric-manager/common/monitor"
+	"io"
+	"net/http"
+)
+
+// deploymentHandler serves the deployment status callback. The Provision Manager, or a component observing its
+// orchestrations, posts the orchestration of a deployment once it has finished, which completes the job waiting for
+// the deployment.
+type deploymentHandler struct {
+	tracker *deployment.Tracker
+	monitor monitor.LogMonitor
+}
+
+func (h *deploymentHandler) status(w http.ResponseWriter, r *http.Request) {
+	deploymentID := chi.URLParam(r, "id")
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	status, err := deployment.ParseStatus(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !h.tracker.Notify(deploymentID, status) {
+		http.Error(w, fmt.Sprintf("deployment %s is not awaited", deploymentID), http.StatusNotFound)
+		return
+	}
+	h.monitor.Debugf("Received status %d of deployment %s", status.State, deploymentID)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,50 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package management
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+	"github.com/metaform/cfm-fulcrum/internal/client"
+	"github.com/metaform/cfm-fulcrum/internal/deployment"
+	"github.com/metaform/connector-fabric-manager/common/monitor"
+	"github.com/metaform/connector-fabric-manager/pmanager/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeploymentHandler_Status(t *testing.T) {
+	tracker := deployment.NewTracker(nil, time.Millisecond, time.Second, nil, monitor.NoopMonitor{})
+	router := chi.NewRouter()
+	router.Post("/deployments/{id}/status", (&deploymentHandler{tracker: tracker, monitor: monitor.NoopMonitor{}}).status)
+	post := func(id string, body string) int {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/deployments/"+id+"/status", strings.NewReader(body)))
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusNotFound, post("deployment-1", `{"state":2}`))
+
+	defer tracker.Expect("deployment-1")()
+	assert.Equal(t, http.StatusBadRequest, post("deployment-1", `not json`))
+	assert.Equal(t, http.StatusNoContent, post("deployment-1", `{"state":2,"ProcessingData":{"endpoints":{}}}`))
+
+	status, err := tracker.Await(context.Background(), "deployment-1", client.JobActionServiceCreate, nil)
+	require.NoError(t, err)
+	assert.Equal(t, api.OrchestrationStateCompleted, status.State)
+}
@@ -0,0 +1,185 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package management
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// clockSkew is the tolerance applied when checking token validity times
+const clockSkew = time.Minute
+
+// signatureAlgorithms are the token signature algorithms accepted by the JWT authenticator
+var signatureAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+}
+
+// JWTConfig configures verification of bearer tokens issued to management API callers
+type JWTConfig struct {
+	JWKSFile   string // Local JSON Web Key Set used to verify signatures; reloaded when it changes
+	Issuer     string // Required iss claim, if set
+	Audience   string // Required aud claim, if set
+	ReadScope  string // Scope claim value granting read access
+	AdminScope string // Scope claim value granting admin access
+}
+
+// jwtAuthenticator grants scopes from the scope claim of a bearer token signed by a key in the JWKS file. RSA and
+// ECDSA signatures are supported.
+type jwtAuthenticator struct {
+	config JWTConfig
+	now    func() time.Time
+
+	mu      sync.Mutex
+	keys    *jose.JSONWebKeySet
+	modTime time.Time
+}
+
+func newJWTAuthenticator(config JWTConfig) (*jwtAuthenticator, error) {
+	if config.JWKSFile == "" {
+		return nil, errors.New("a JWKS file is required")
+	}
+	if config.ReadScope == "" || config.AdminScope == "" {
+		return nil, errors.New("read and admin scopes are required")
+	}
+	a := &jwtAuthenticator{config: config, now: time.Now}
+	if _, err := a.publicKeys(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *jwtAuthenticator) authenticate(r *http.Request) (Scope, error) {
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return ScopeNone, errUnauthenticated
+	}
+	claims, err := a.verify(token)
+	if err != nil {
+		return ScopeNone, err
+	}
+
+	scopes := claims.scopes()
+	switch {
+	case slices.Contains(scopes, a.config.AdminScope):
+		return ScopeAdmin, nil
+	case slices.Contains(scopes, a.config.ReadScope):
+		return ScopeRead, nil
+	}
+	return ScopeNone, nil
+}
+
+// scopeClaims are the claims that carry the scopes granted to the caller
+type scopeClaims struct {
+	Scope string   `json:"scope"`
+	Scp   []string `json:"scp"`
+}
+
+func (c scopeClaims) scopes() []string {
+	return append(strings.Fields(c.Scope), c.Scp...)
+}
+
+// verify checks the token signature and registered claims and returns the scope claims. Tokens must expire.
+func (a *jwtAuthenticator) verify(token string) (*scopeClaims, error) {
+	parsed, err := jwt.ParseSigned(token, signatureAlgorithms)
+	if err != nil {
+		return nil, fmt.Errorf("malformed token: %w", err)
+	}
+	keys, err := a.publicKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	var registered jwt.Claims
+	var claims scopeClaims
+	if err := parsed.Claims(keys, &registered, &claims); err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+	if registered.Expiry == nil {
+		return nil, errors.New("token does not expire")
+	}
+	expected := jwt.Expected{Issuer: a.config.Issuer, Time: a.now()}
+	if a.config.Audience != "" {
+		expected.AnyAudience = jwt.Audience{a.config.Audience}
+	}
+	if err := registered.ValidateWithLeeway(expected, clockSkew); err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+	return &claims, nil
+}
+
+// publicKeys returns the keys of the JWKS file, reading the file again if it changed
+func (a *jwtAuthenticator) publicKeys() (*jose.JSONWebKeySet, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	info, err := os.Stat(a.config.JWKSFile)
+	if err != nil {
+		if a.keys != nil {
+			return a.keys, nil
+		}
+		return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+	if a.keys != nil && info.ModTime().Equal(a.modTime) {
+		return a.keys, nil
+	}
+
+	data, err := os.ReadFile(a.config.JWKSFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		if a.keys != nil {
+			// keep the previous keys while the file is being replaced
+			return a.keys, nil
+		}
+		return nil, err
+	}
+	a.keys = keys
+	a.modTime = info.ModTime()
+	return keys, nil
+}
+
+// parseJWKS returns the public signing keys of a JSON Web Key Set
+func parseJWKS(data []byte) (*jose.JSONWebKeySet, error) {
+	var set jose.JSONWebKeySet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS file: %w", err)
+	}
+	keys := &jose.JSONWebKeySet{}
+	for _, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		if !key.IsPublic() {
+			return nil, fmt.Errorf("invalid key %q in JWKS file: not a public key", key.KeyID)
+		}
+		keys.Keys = append(keys.Keys, key)
+	}
+	if len(keys.Keys) == 0 {
+		return nil, errors.New("JWKS file contains no signing keys")
+	}
+	return keys, nil
+}
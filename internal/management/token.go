@@ -0,0 +1,120 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package management
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/metaform/cfm-fulcrum/internal/client"
+	"github.com/metaform/connector-fabric-manager/common/monitor"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// tokenHandler serves the Fulcrum token endpoints. A new token is validated against Fulcrum Core before it replaces
+// the current one, and is persisted if a secret file is configured.
+type tokenHandler struct {
+	fulcrumClient client.FulcrumClient
+	tracker       *client.TokenTracker // nil if the token cannot be updated at runtime
+	monitor       monitor.LogMonitor
+	mu            sync.Mutex // serializes updates so that a rollback cannot restore a token another update replaced
+}
+
+// tokenStatus is returned by GET /fulcrum-token. It never contains the token itself.
+type tokenStatus struct {
+	client.TokenInfo
+	AgeSeconds int64 `json:"ageSeconds"`
+}
+
+func (h *tokenHandler) update(w http.ResponseWriter, r *http.Request) {
+	var result map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&result); err != nil {
+		http.Error(w, fmt.Sprintf("failed to unmarshal JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	// Extract token from the request body
+	token, ok := result["token"].(string)
+	if !ok {
+		http.Error(w, "token field is required and must be a string", http.StatusBadRequest)
+		return
+	}
+
+	// Optional expiry for tokens that do not carry one
+	var expiresAt *time.Time
+	if value, found := result["expiresAt"]; found {
+		str, _ := value.(string)
+		parsed, err := time.Parse(time.RFC3339, str)
+		if err != nil {
+			http.Error(w, "expiresAt must be an RFC 3339 timestamp", http.StatusBadRequest)
+			return
+		}
+		expiresAt = &parsed
+	}
+
+	if h.tracker == nil {
+		http.Error(w, "the configured authenticator does not accept token updates", http.StatusConflict)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	agentInfo, err := h.fulcrumClient.ValidateToken(r.Context(), token)
+	if err != nil {
+		if client.HasStatus(err, http.StatusUnauthorized) || client.HasStatus(err, http.StatusForbidden) {
+			h.monitor.Warnf("Rejected token update: token not accepted by Fulcrum Core")
+			http.Error(w, "token not accepted by Fulcrum Core", http.StatusUnprocessableEntity)
+			return
+		}
+		h.monitor.Warnf("Unable to validate token: %v", err)
+		http.Error(w, fmt.Sprintf("unable to validate token: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	previous, err := h.tracker.Persist(token)
+	if err != nil {
+		h.monitor.Severef("Error persisting token: %v", err)
+		http.Error(w, fmt.Sprintf("error updating token: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.fulcrumClient.UpdateToken(token); err != nil {
+		if restoreErr := h.tracker.Restore(previous); restoreErr != nil {
+			h.monitor.Severef("Error restoring persisted token: %v", restoreErr)
+		}
+		h.monitor.Severef("Error updating token: %v", err)
+		http.Error(w, fmt.Sprintf("error updating token: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.tracker.Accept(token, expiresAt, time.Now())
+	h.monitor.Infof("Fulcrum token updated for agent %v", agentInfo["id"])
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	response := response{Message: "OK"}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *tokenHandler) status(w http.ResponseWriter, _ *http.Request) {
+	if h.tracker == nil {
+		http.Error(w, "token is managed by the configured authenticator", http.StatusNotFound)
+		return
+	}
+	info := h.tracker.Info()
+	status := tokenStatus{TokenInfo: info, AgeSeconds: int64(info.Age(time.Now()).Seconds())}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
@@ -0,0 +1,132 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package management
+
+import (
+	"context"
+	"errors"
+	"github.com/metaform/cfm-fulcrum/internal/client"
+	"github.com/metaform/cfm-fulcrum/internal/client/mocks"
+	"github.com/metaform/connector-fabric-manager/common/monitor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTokenHandler_Update(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(secretFile, []byte("old"), 0600))
+	fulcrumClient := mocks.NewFulcrumClient(t)
+	fulcrumClient.EXPECT().ValidateToken(mock.Anything, "new").Return(map[string]any{"id": "agent-1"}, nil)
+	fulcrumClient.EXPECT().UpdateToken("new").Return(nil)
+
+	handler := newTestTokenHandler(fulcrumClient, secretFile)
+	rec := postToken(handler, `{"token":"new","expiresAt":"2030-01-01T00:00:00Z"}`)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assertSecretFile(t, secretFile, "new")
+	info := handler.tracker.Info()
+	require.NotNil(t, info.ExpiresAt)
+	assert.Equal(t, 2030, info.ExpiresAt.Year())
+}
+
+func TestTokenHandler_UpdateRejected(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(secretFile, []byte("old"), 0600))
+	fulcrumClient := mocks.NewFulcrumClient(t)
+	fulcrumClient.EXPECT().ValidateToken(mock.Anything, "new").
+		Return(nil, &client.HTTPError{StatusCode: http.StatusUnauthorized})
+
+	handler := newTestTokenHandler(fulcrumClient, secretFile)
+	rec := postToken(handler, `{"token":"new"}`)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assertSecretFile(t, secretFile, "old")
+}
+
+func TestTokenHandler_UpdateRollsBack(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(secretFile, []byte("old"), 0600))
+	fulcrumClient := mocks.NewFulcrumClient(t)
+	fulcrumClient.EXPECT().ValidateToken(mock.Anything, "new").Return(map[string]any{"id": "agent-1"}, nil)
+	fulcrumClient.EXPECT().UpdateToken("new").Return(errors.New("update failed"))
+
+	handler := newTestTokenHandler(fulcrumClient, secretFile)
+	acceptedAt := handler.tracker.Info().AcceptedAt
+	rec := postToken(handler, `{"token":"new"}`)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assertSecretFile(t, secretFile, "old")
+	assert.Equal(t, acceptedAt, handler.tracker.Info().AcceptedAt)
+}
+
+func TestTokenHandler_ConcurrentUpdates(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(secretFile, []byte("old"), 0600))
+
+	var inFlight, overlapping atomic.Int32
+	fulcrumClient := mocks.NewFulcrumClient(t)
+	fulcrumClient.EXPECT().ValidateToken(mock.Anything, mock.Anything).Run(func(context.Context, string) {
+		if inFlight.Add(1) > 1 {
+			overlapping.Add(1)
+		}
+		time.Sleep(20 * time.Millisecond)
+		inFlight.Add(-1)
+	}).Return(map[string]any{"id": "agent-1"}, nil)
+	fulcrumClient.EXPECT().UpdateToken("rejected").Return(errors.New("update failed"))
+	fulcrumClient.EXPECT().UpdateToken("new").Return(nil)
+
+	handler := newTestTokenHandler(fulcrumClient, secretFile)
+	var wg sync.WaitGroup
+	for _, token := range []string{"rejected", "new"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			postToken(handler, `{"token":"`+token+`"}`)
+		}()
+	}
+	wg.Wait()
+
+	assert.Zero(t, overlapping.Load())
+	// the failed update must not restore a token that was replaced in the meantime
+	assertSecretFile(t, secretFile, "new")
+}
+
+func newTestTokenHandler(fulcrumClient client.FulcrumClient, secretFile string) *tokenHandler {
+	return &tokenHandler{
+		fulcrumClient: fulcrumClient,
+		tracker:       client.NewTokenTracker("old", secretFile, time.Now().Add(-time.Hour)),
+		monitor:       monitor.NoopMonitor{},
+	}
+}
+
+func postToken(handler *tokenHandler, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler.update(rec, httptest.NewRequest(http.MethodPost, "/fulcrum-token", strings.NewReader(body)))
+	return rec
+}
+
+func assertSecretFile(t *testing.T, path string, expected string) {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(data))
+}
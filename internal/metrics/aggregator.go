@@ -0,0 +1,125 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package metrics
+
+import (
+	"fmt"
+	"github.com/metaform/cfm-fulcrum/internal/client"
+	"sync"
+	"time"
+)
+
+// Aggregation is the function used to coalesce samples of a metric type
+type Aggregation string
+
+const (
+	AggregationSum  Aggregation = "sum"
+	AggregationMax  Aggregation = "max"
+	AggregationLast Aggregation = "last"
+)
+
+// ParseAggregation returns the aggregation with the given name
+func ParseAggregation(name string) (Aggregation, error) {
+	switch aggregation := Aggregation(name); aggregation {
+	case AggregationSum, AggregationMax, AggregationLast:
+		return aggregation, nil
+	}
+	return "", fmt.Errorf("unsupported aggregation %q, expected %s, %s or %s", name, AggregationSum, AggregationMax, AggregationLast)
+}
+
+func (a Aggregation) apply(current float64, sample float64) float64 {
+	switch a {
+	case AggregationSum:
+		return current + sample
+	case AggregationMax:
+		return max(current, sample)
+	}
+	return sample
+}
+
+// Aggregator coalesces metric samples per service, resource and metric type over a window, so that a single entry per
+// resource and type is reported for each window. Samples of different resources, e.g. the connectors of a service, are
+// kept apart.
+type Aggregator struct {
+	window       time.Duration
+	aggregations map[string]Aggregation
+
+	mu         sync.Mutex
+	started    time.Time
+	aggregates map[aggregateKey]*client.MetricEntry
+	order      []aggregateKey
+}
+
+type aggregateKey struct {
+	externalID string
+	resourceID string
+	typeName   string
+}
+
+// NewAggregator creates an aggregator coalescing samples with the aggregation configured for their type name.
+// Samples of other types keep the last value. A window of zero reports the samples of every collection.
+func NewAggregator(window time.Duration, aggregations map[string]Aggregation) *Aggregator {
+	return &Aggregator{
+		window:       window,
+		aggregations: aggregations,
+		aggregates:   make(map[aggregateKey]*client.MetricEntry),
+	}
+}
+
+// Add coalesces samples into the current window, which starts with the first sample
+func (a *Aggregator) Add(now time.Time, samples ...*client.MetricEntry) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, sample := range samples {
+		if len(a.aggregates) == 0 {
+			a.started = now
+		}
+		key := aggregateKey{externalID: sample.ExternalID, resourceID: sample.ResourceID, typeName: sample.TypeName}
+		aggregate, found := a.aggregates[key]
+		if !found {
+			copied := *sample
+			a.aggregates[key] = &copied
+			a.order = append(a.order, key)
+			continue
+		}
+		aggregate.Value = a.aggregation(sample.TypeName).apply(aggregate.Value, sample.Value)
+	}
+}
+
+// Due returns true if the current window has elapsed
+func (a *Aggregator) Due(now time.Time) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.aggregates) > 0 && now.Sub(a.started) >= a.window
+}
+
+// Drain returns the coalesced entries of the current window in the order they were first sampled and starts a new
+// window
+func (a *Aggregator) Drain() []*client.MetricEntry {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	entries := make([]*client.MetricEntry, len(a.order))
+	for i, key := range a.order {
+		entries[i] = a.aggregates[key]
+	}
+	a.aggregates = make(map[aggregateKey]*client.MetricEntry)
+	a.order = nil
+	return entries
+}
+
+func (a *Aggregator) aggregation(typeName string) Aggregation {
+	if aggregation, found := a.aggregations[typeName]; found {
+		return aggregation
+	}
+	return AggregationLast
+}
@@ -0,0 +1,176 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"github.com/metaform/cfm-fulcrum/internal/client"
+	"github.com/metaform/cfm-fulcrum/internal/deployment"
+	"github.com/metaform/connector-fabric-manager/common/monitor"
+	"github.com/metaform/connector-fabric-manager/common/system"
+	"time"
+)
+
+const (
+	intervalKey         = "metrics.interval"
+	usagePathKey        = "metrics.usage_path"
+	bufferSizeKey       = "metrics.buffer_size"
+	batchSizeKey        = "metrics.batch_size"
+	windowKey           = "metrics.window"
+	deploymentsTypeKey  = "metrics.types.deployments"
+	cpuTypeKey          = "metrics.types.cpu"
+	memoryTypeKey       = "metrics.types.memory"
+	dataTransferTypeKey = "metrics.types.data_transfer"
+
+	// aggregations are configured per measurement, e.g. metrics.aggregation.cpu
+	aggregationKeyPrefix = "metrics.aggregation"
+
+	defaultInterval   = 5 * time.Minute
+	defaultBufferSize = 10000
+	defaultBatchSize  = 100
+)
+
+// MetricsServiceAssembly periodically reports the usage of provisioned services to Fulcrum Core. Collection is
+// disabled if the interval is not positive or no usage path is configured, as the Provision Manager does not provide a
+// usage endpoint out of the box.
+type MetricsServiceAssembly struct {
+	system.DefaultServiceAssembly
+	collector *Collector
+	interval  time.Duration
+	monitor   monitor.LogMonitor
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+func (a *MetricsServiceAssembly) Name() string {
+	return "Metrics Collector"
+}
+
+func (a *MetricsServiceAssembly) Requires() []system.ServiceType {
+	return []system.ServiceType{client.FulcrumClientKey, client.ApiClientKey, deployment.InventoryKey}
+}
+
+func (a *MetricsServiceAssembly) Init(context *system.InitContext) error {
+	a.monitor = context.LogMonitor
+	a.interval = defaultInterval
+	if context.Config.IsSet(intervalKey) {
+		a.interval = context.Config.GetDuration(intervalKey)
+	}
+	usagePath := context.GetConfigStrOrDefault(usagePathKey, "")
+	if usagePath == "" {
+		context.LogMonitor.Infof("%s not set, metrics collection disabled", usagePathKey)
+		return nil
+	}
+	bufferSize := context.GetConfigIntOrDefault(bufferSizeKey, defaultBufferSize)
+	if bufferSize <= 0 {
+		return fmt.Errorf("%s must be greater than 0, was: %d", bufferSizeKey, bufferSize)
+	}
+
+	batchSize := context.GetConfigIntOrDefault(batchSizeKey, defaultBatchSize)
+	if batchSize <= 0 {
+		return fmt.Errorf("%s must be greater than 0, was: %d", batchSizeKey, batchSize)
+	}
+	var window time.Duration
+	if context.Config.IsSet(windowKey) {
+		window = context.Config.GetDuration(windowKey)
+	}
+
+	defaults := DefaultMetricTypes()
+	types := MetricTypes{
+		Deployments:  context.GetConfigStrOrDefault(deploymentsTypeKey, defaults.Deployments),
+		CPU:          context.GetConfigStrOrDefault(cpuTypeKey, defaults.CPU),
+		Memory:       context.GetConfigStrOrDefault(memoryTypeKey, defaults.Memory),
+		DataTransfer: context.GetConfigStrOrDefault(dataTransferTypeKey, defaults.DataTransfer),
+	}
+
+	aggregations, err := loadAggregations(context, types)
+	if err != nil {
+		return err
+	}
+
+	fulcrumClient := context.Registry.Resolve(client.FulcrumClientKey).(client.FulcrumClient)
+	apiClient := context.Registry.Resolve(client.ApiClientKey).(client.ApiClient)
+	inventory := context.Registry.Resolve(deployment.InventoryKey).(*deployment.Inventory)
+
+	source := NewPManagerUsageSource(apiClient, usagePath)
+	aggregator := NewAggregator(window, aggregations)
+	a.collector = NewCollector(inventory, source, fulcrumClient, types, aggregator, NewBuffer(bufferSize), batchSize, context.LogMonitor)
+	return nil
+}
+
+func (a *MetricsServiceAssembly) Start(_ *system.StartContext) error {
+	if a.collector == nil {
+		return nil
+	}
+	if a.interval <= 0 {
+		a.monitor.Infof("Metrics collection disabled")
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
+	a.done = make(chan struct{})
+
+	go func() {
+		defer close(a.done)
+		ticker := time.NewTicker(a.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := a.collector.Collect(ctx); err != nil && ctx.Err() == nil {
+					a.monitor.Warnf("Error collecting metrics: %v", err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (a *MetricsServiceAssembly) Finalize() error {
+	if a.cancel == nil {
+		return nil
+	}
+	a.cancel()
+	<-a.done
+	return nil
+}
+
+// loadAggregations returns the configured aggregation of each metric type. Levels are reported as their peak over the
+// window and data transfer as its total.
+func loadAggregations(context *system.InitContext, types MetricTypes) (map[string]Aggregation, error) {
+	measurements := []struct {
+		name        string
+		typeName    string
+		aggregation Aggregation
+	}{
+		{name: "deployments", typeName: types.Deployments, aggregation: AggregationLast},
+		{name: "cpu", typeName: types.CPU, aggregation: AggregationMax},
+		{name: "memory", typeName: types.Memory, aggregation: AggregationMax},
+		{name: "data_transfer", typeName: types.DataTransfer, aggregation: AggregationSum},
+	}
+	aggregations := make(map[string]Aggregation)
+	for _, measurement := range measurements {
+		key := fmt.Sprintf("%s.%s", aggregationKeyPrefix, measurement.name)
+		aggregation, err := ParseAggregation(context.GetConfigStrOrDefault(key, string(measurement.aggregation)))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		if measurement.typeName != "" {
+			aggregations[measurement.typeName] = aggregation
+		}
+	}
+	return aggregations, nil
+}
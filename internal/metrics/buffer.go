@@ -0,0 +1,143 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"github.com/metaform/cfm-fulcrum/internal/client"
+	"net/http"
+	"sync"
+)
+
+// Buffer holds metric entries until they are accepted by Fulcrum Core, so entries collected during an outage are
+// replayed once Fulcrum Core is reachable again. When the buffer is full, the oldest entries are dropped.
+type Buffer struct {
+	capacity int
+
+	mu      sync.Mutex
+	entries []*client.MetricEntry
+}
+
+// NewBuffer creates a buffer holding up to capacity entries
+func NewBuffer(capacity int) *Buffer {
+	return &Buffer{capacity: capacity}
+}
+
+// Add appends entries to the buffer and returns the number of old entries dropped to make room for them
+func (b *Buffer) Add(entries ...*client.MetricEntry) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.entries = append(b.entries, entries...)
+	dropped := max(len(b.entries)-b.capacity, 0)
+	if dropped > 0 {
+		b.entries = append([]*client.MetricEntry(nil), b.entries[dropped:]...)
+	}
+	return dropped
+}
+
+// Len returns the number of buffered entries
+func (b *Buffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.entries)
+}
+
+// Flush sends buffered entries in batches of up to batchSize, in the order they were added. Entries that fail with a
+// retryable error stay in the buffer and sending stops after the batch, returning the first such error. Entries
+// rejected with a non-retryable error are discarded and passed to rejected.
+func (b *Buffer) Flush(
+	ctx context.Context,
+	batchSize int,
+	send func(context.Context, []*client.MetricEntry) error,
+	rejected func(*client.MetricEntry, error)) (int, error) {
+	sent := 0
+	for {
+		batch := b.head(batchSize)
+		if len(batch) == 0 {
+			return sent, nil
+		}
+
+		errs := entryErrors(batch, send(ctx, batch))
+		var retryErr error
+		done := make(map[*client.MetricEntry]bool, len(batch))
+		for i, entry := range batch {
+			switch err := errs[i]; {
+			case err == nil:
+				done[entry] = true
+				sent++
+			case isRetryable(err):
+				if retryErr == nil {
+					retryErr = err
+				}
+			default:
+				done[entry] = true
+				rejected(entry, err)
+			}
+		}
+		b.remove(done)
+		if retryErr != nil {
+			return sent, retryErr
+		}
+	}
+}
+
+// head returns up to n entries from the head of the buffer
+func (b *Buffer) head(n int) []*client.MetricEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]*client.MetricEntry(nil), b.entries[:min(n, len(b.entries))]...)
+}
+
+// remove removes the given entries, unless they were already dropped while being sent
+func (b *Buffer) remove(done map[*client.MetricEntry]bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	remaining := b.entries[:0]
+	for _, entry := range b.entries {
+		if !done[entry] {
+			remaining = append(remaining, entry)
+		}
+	}
+	clear(b.entries[len(remaining):])
+	b.entries = remaining
+}
+
+// entryErrors returns the error of each entry of a batch. An error that is not a *client.MetricsError applies to
+// every entry.
+func entryErrors(batch []*client.MetricEntry, err error) []error {
+	errs := make([]error, len(batch))
+	var metricsErr *client.MetricsError
+	switch {
+	case err == nil:
+	case errors.As(err, &metricsErr):
+		for _, failure := range metricsErr.Failures {
+			errs[failure.Index] = failure.Err
+		}
+	default:
+		for i := range errs {
+			errs[i] = err
+		}
+	}
+	return errs
+}
+
+// isRetryable returns false for errors indicating that Fulcrum Core will never accept the entry. Authentication
+// failures are retryable since the entry is accepted once the agent token is replaced.
+func isRetryable(err error) bool {
+	var httpErr *client.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Retryable || httpErr.StatusCode == http.StatusUnauthorized || httpErr.StatusCode == http.StatusForbidden
+	}
+	return true
+}
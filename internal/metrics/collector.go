@@ -0,0 +1,141 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"github.com/metaform/cfm-fulcrum/internal/client"
+	"github.com/metaform/cfm-fulcrum/internal/deployment"
+	"github.com/metaform/connector-fabric-manager/common/monitor"
+	"time"
+)
+
+// MetricTypes maps the collected measurements to metric type names defined in Fulcrum Core. A measurement with an
+// empty type name is not reported.
+type MetricTypes struct {
+	Deployments  string
+	CPU          string
+	Memory       string
+	DataTransfer string
+}
+
+// DefaultMetricTypes returns the default metric type names
+func DefaultMetricTypes() MetricTypes {
+	return MetricTypes{
+		Deployments:  "cfm.deployments",
+		CPU:          "cfm.connector.cpu",
+		Memory:       "cfm.connector.memory",
+		DataTransfer: "cfm.data_transfer",
+	}
+}
+
+// Collector gathers the usage of every service in the inventory and reports it to Fulcrum Core as metric entries
+type Collector struct {
+	inventory     *deployment.Inventory
+	source        UsageSource
+	fulcrumClient client.FulcrumClient
+	types         MetricTypes
+	aggregator    *Aggregator
+	buffer        *Buffer
+	batchSize     int
+	monitor       monitor.LogMonitor
+}
+
+// NewCollector creates a collector. Samples are coalesced by the aggregator, and the resulting entries are held in
+// buffer until they are reported in batches of up to batchSize.
+func NewCollector(
+	inventory *deployment.Inventory,
+	source UsageSource,
+	fulcrumClient client.FulcrumClient,
+	types MetricTypes,
+	aggregator *Aggregator,
+	buffer *Buffer,
+	batchSize int,
+	monitor monitor.LogMonitor) *Collector {
+	return &Collector{
+		inventory:     inventory,
+		source:        source,
+		fulcrumClient: fulcrumClient,
+		types:         types,
+		aggregator:    aggregator,
+		buffer:        buffer,
+		batchSize:     batchSize,
+		monitor:       monitor,
+	}
+}
+
+// Collect gathers the usage of all services. Once the aggregation window has elapsed, the aggregated entries are
+// reported together with any entries buffered during an earlier outage. Services whose usage cannot be read are
+// skipped until the next collection.
+func (c *Collector) Collect(ctx context.Context) error {
+	var samples []*client.MetricEntry
+	for _, service := range c.inventory.List() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		usage, err := c.source.GetUsage(ctx, service.ExternalID)
+		if err != nil {
+			c.monitor.Warnf("Unable to read usage of service %s: %v", service.ExternalID, err)
+			continue
+		}
+		samples = append(samples, c.entries(service, usage)...)
+	}
+
+	now := time.Now()
+	c.aggregator.Add(now, samples...)
+	if c.aggregator.Due(now) {
+		if dropped := c.buffer.Add(c.aggregator.Drain()...); dropped > 0 {
+			c.monitor.Warnf("Metrics buffer full, dropped %d metric entries", dropped)
+		}
+	}
+	return c.Flush(ctx)
+}
+
+// Flush reports the buffered entries to Fulcrum Core
+func (c *Collector) Flush(ctx context.Context) error {
+	sent, err := c.buffer.Flush(ctx, c.batchSize, c.fulcrumClient.ReportMetrics, func(entry *client.MetricEntry, err error) {
+		c.monitor.Warnf("Discarding metric %s of service %s: %v", entry.TypeName, entry.ExternalID, err)
+	})
+	if sent > 0 {
+		c.monitor.Debugf("Reported %d metric entries", sent)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to report metrics, %d entries buffered: %w", c.buffer.Len(), err)
+	}
+	return nil
+}
+
+// entries converts the usage of a service into metric entries. Service-level measurements use the deployment as
+// resource, connector measurements the connector.
+func (c *Collector) entries(service deployment.Service, usage *Usage) []*client.MetricEntry {
+	var entries []*client.MetricEntry
+	add := func(typeName string, resourceID string, value float64) {
+		if typeName == "" {
+			return
+		}
+		entries = append(entries, &client.MetricEntry{
+			ExternalID: service.ExternalID,
+			ResourceID: resourceID,
+			Value:      value,
+			TypeName:   typeName,
+		})
+	}
+	add(c.types.Deployments, service.DeploymentID, float64(usage.Deployments))
+	for _, connector := range usage.Connectors {
+		add(c.types.CPU, connector.ID, connector.CPU)
+		add(c.types.Memory, connector.ID, connector.MemoryBytes)
+	}
+	add(c.types.DataTransfer, service.DeploymentID, usage.DataTransferBytes)
+	return entries
+}
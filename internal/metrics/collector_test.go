@@ -0,0 +1,173 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"github.com/metaform/cfm-fulcrum/internal/client"
+	"github.com/metaform/cfm-fulcrum/internal/client/mocks"
+	"github.com/metaform/cfm-fulcrum/internal/deployment"
+	"github.com/metaform/connector-fabric-manager/common/monitor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCollector_Collect(t *testing.T) {
+	inventory := newTestInventory(t, "external-1", "external-2")
+	source := usageFunc(func(externalID string) (*Usage, error) {
+		if externalID == "external-2" {
+			return nil, errors.New("not found")
+		}
+		return &Usage{
+			Deployments: 2,
+			Connectors: []ConnectorUsage{
+				{ID: "connector-1", CPU: 0.5, MemoryBytes: 512},
+				{ID: "connector-2", CPU: 0.25, MemoryBytes: 256},
+			},
+			DataTransferBytes: 1024,
+		}, nil
+	})
+	types := DefaultMetricTypes()
+	types.DataTransfer = ""
+	aggregator := NewAggregator(0, map[string]Aggregation{types.CPU: AggregationSum, types.Memory: AggregationMax})
+
+	fulcrumClient := mocks.NewFulcrumClient(t)
+	var reported []client.MetricEntry
+	fulcrumClient.EXPECT().ReportMetrics(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, batch []*client.MetricEntry) error {
+		for _, entry := range batch {
+			reported = append(reported, *entry)
+		}
+		return nil
+	}).Once()
+
+	collector := NewCollector(inventory, source, fulcrumClient, types, aggregator, NewBuffer(10), 10, monitor.NoopMonitor{})
+	require.NoError(t, collector.Collect(context.Background()))
+
+	assert.Equal(t, []client.MetricEntry{
+		{ExternalID: "external-1", ResourceID: "deployment-external-1", Value: 2, TypeName: "cfm.deployments"},
+		{ExternalID: "external-1", ResourceID: "connector-1", Value: 0.5, TypeName: "cfm.connector.cpu"},
+		{ExternalID: "external-1", ResourceID: "connector-1", Value: 512, TypeName: "cfm.connector.memory"},
+		{ExternalID: "external-1", ResourceID: "connector-2", Value: 0.25, TypeName: "cfm.connector.cpu"},
+		{ExternalID: "external-1", ResourceID: "connector-2", Value: 256, TypeName: "cfm.connector.memory"},
+	}, reported)
+}
+
+func TestCollector_ReplaysAfterOutage(t *testing.T) {
+	inventory := newTestInventory(t, "external-1")
+	source := usageFunc(func(string) (*Usage, error) {
+		return &Usage{Deployments: 1}, nil
+	})
+	types := MetricTypes{Deployments: "cfm.deployments"}
+
+	fulcrumClient := mocks.NewFulcrumClient(t)
+	fulcrumClient.EXPECT().ReportMetrics(mock.Anything, mock.Anything).
+		Return(&client.HTTPError{StatusCode: http.StatusServiceUnavailable, Retryable: true}).Once()
+
+	collector := NewCollector(inventory, source, fulcrumClient, types, NewAggregator(0, nil), NewBuffer(10), 10, monitor.NoopMonitor{})
+	assert.ErrorContains(t, collector.Collect(context.Background()), "1 entries buffered")
+
+	fulcrumClient.EXPECT().ReportMetrics(mock.Anything, mock.MatchedBy(func(batch []*client.MetricEntry) bool {
+		return len(batch) == 2
+	})).Return(nil).Once()
+	require.NoError(t, collector.Collect(context.Background()))
+	assert.Equal(t, 0, collector.buffer.Len())
+}
+
+func TestCollector_PartialFailure(t *testing.T) {
+	inventory := newTestInventory(t, "external-1", "external-2", "external-3")
+	source := usageFunc(func(string) (*Usage, error) {
+		return &Usage{Deployments: 1}, nil
+	})
+	types := MetricTypes{Deployments: "cfm.deployments"}
+
+	fulcrumClient := mocks.NewFulcrumClient(t)
+	fulcrumClient.EXPECT().ReportMetrics(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, batch []*client.MetricEntry) error {
+		return &client.MetricsError{Total: len(batch), Failures: []client.MetricFailure{
+			{Index: 0, Entry: batch[0], Err: &client.HTTPError{StatusCode: http.StatusBadRequest}},
+			{Index: 2, Entry: batch[2], Err: &client.HTTPError{StatusCode: http.StatusBadGateway, Retryable: true}},
+		}}
+	}).Once()
+
+	collector := NewCollector(inventory, source, fulcrumClient, types, NewAggregator(0, nil), NewBuffer(10), 10, monitor.NoopMonitor{})
+	assert.Error(t, collector.Collect(context.Background()))
+
+	// the rejected and accepted entries are removed, the entry that failed with a retryable error is kept
+	require.Equal(t, 1, collector.buffer.Len())
+	assert.Equal(t, "external-3", collector.buffer.head(1)[0].ExternalID)
+}
+
+func TestAggregator_Window(t *testing.T) {
+	aggregator := NewAggregator(time.Minute, map[string]Aggregation{"sum": AggregationSum, "max": AggregationMax})
+	start := time.Now()
+
+	aggregator.Add(start,
+		&client.MetricEntry{ExternalID: "external-1", ResourceID: "r", TypeName: "sum", Value: 1},
+		&client.MetricEntry{ExternalID: "external-1", ResourceID: "r", TypeName: "max", Value: 5},
+		&client.MetricEntry{ExternalID: "external-1", ResourceID: "r", TypeName: "last", Value: 1})
+	assert.False(t, aggregator.Due(start.Add(30*time.Second)))
+	aggregator.Add(start.Add(30*time.Second),
+		&client.MetricEntry{ExternalID: "external-1", ResourceID: "r", TypeName: "sum", Value: 2},
+		&client.MetricEntry{ExternalID: "external-1", ResourceID: "r", TypeName: "max", Value: 3},
+		&client.MetricEntry{ExternalID: "external-1", ResourceID: "r", TypeName: "last", Value: 7},
+		&client.MetricEntry{ExternalID: "external-1", ResourceID: "other", TypeName: "max", Value: 9})
+	require.True(t, aggregator.Due(start.Add(time.Minute)))
+
+	var values []float64
+	var resources []string
+	for _, entry := range aggregator.Drain() {
+		values = append(values, entry.Value)
+		resources = append(resources, entry.ResourceID)
+	}
+	assert.Equal(t, []float64{3, 5, 7, 9}, values)
+	assert.Equal(t, []string{"r", "r", "r", "other"}, resources)
+	assert.False(t, aggregator.Due(start.Add(2*time.Minute)))
+}
+
+func TestBuffer_DropsOldest(t *testing.T) {
+	buffer := NewBuffer(2)
+	assert.Equal(t, 0, buffer.Add(&client.MetricEntry{ExternalID: "1"}))
+	assert.Equal(t, 1, buffer.Add(&client.MetricEntry{ExternalID: "2"}, &client.MetricEntry{ExternalID: "3"}))
+
+	var sent []string
+	count, err := buffer.Flush(context.Background(), 1, func(_ context.Context, batch []*client.MetricEntry) error {
+		sent = append(sent, batch[0].ExternalID)
+		return nil
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, []string{"2", "3"}, sent)
+}
+
+type usageFunc func(string) (*Usage, error)
+
+func (f usageFunc) GetUsage(_ context.Context, externalID string) (*Usage, error) {
+	return f(externalID)
+}
+
+func newTestInventory(t *testing.T, externalIDs ...string) *deployment.Inventory {
+	inventory, err := deployment.NewInventory("")
+	require.NoError(t, err)
+	for _, externalID := range externalIDs {
+		require.NoError(t, inventory.Put(deployment.Service{
+			ExternalID:   externalID,
+			ServiceID:    "service-" + externalID,
+			DeploymentID: "deployment-" + externalID,
+		}))
+	}
+	return inventory
+}
@@ -0,0 +1,64 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/metaform/cfm-fulcrum/internal/client"
+	"net/url"
+)
+
+// Usage is the resource usage of a service provisioned through CFM
+type Usage struct {
+	Deployments       int              `json:"deployments"`
+	Connectors        []ConnectorUsage `json:"connectors"`
+	DataTransferBytes float64          `json:"dataTransferBytes"`
+}
+
+// ConnectorUsage is the resource usage of a connector belonging to a service
+type ConnectorUsage struct {
+	ID          string  `json:"id"`
+	CPU         float64 `json:"cpu"`
+	MemoryBytes float64 `json:"memoryBytes"`
+}
+
+// UsageSource returns the current usage of a service identified by its external ID
+type UsageSource interface {
+	GetUsage(ctx context.Context, externalID string) (*Usage, error)
+}
+
+// PManagerUsageSource reads service usage from a usage endpoint served alongside the Provision Manager API, which
+// returns the usage of a service at GET {usagePath}/{externalId}
+type PManagerUsageSource struct {
+	apiClient client.ApiClient
+	usagePath string
+}
+
+func NewPManagerUsageSource(apiClient client.ApiClient, usagePath string) *PManagerUsageSource {
+	return &PManagerUsageSource{apiClient: apiClient, usagePath: usagePath}
+}
+
+// GetUsage returns the usage of the service
+func (s *PManagerUsageSource) GetUsage(ctx context.Context, externalID string) (*Usage, error) {
+	body, err := s.apiClient.GetFromPManager(ctx, fmt.Sprintf("%s/%s", s.usagePath, url.PathEscape(externalID)))
+	if err != nil {
+		return nil, err
+	}
+	var usage Usage
+	if err := json.Unmarshal(body, &usage); err != nil {
+		return nil, fmt.Errorf("failed to decode usage of service %s: %w", externalID, err)
+	}
+	return &usage, nil
+}
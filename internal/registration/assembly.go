@@ -0,0 +1,159 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package registration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/metaform/cfm-fulcrum/internal/client"
+	"github.com/metaform/connector-fabric-manager/common/monitor"
+	"github.com/metaform/connector-fabric-manager/common/system"
+	"time"
+)
+
+// RegistrationKey resolves the *Registration holding the verified agent identity
+const RegistrationKey system.ServiceType = "registration:Registration"
+
+const (
+	onUnauthorizedKey = "registration.on_unauthorized"
+	retryIntervalKey  = "registration.retry_interval"
+	timeoutKey        = "registration.timeout"
+
+	defaultRetryInterval = 15 * time.Second
+	defaultTimeout       = 10 * time.Second
+)
+
+// Supported values of the registration.on_unauthorized setting
+const (
+	// OnUnauthorizedFail aborts startup if the agent token is rejected
+	OnUnauthorizedFail = "fail"
+	// OnUnauthorizedWait starts the agent without processing jobs until a valid token is provided
+	OnUnauthorizedWait = "wait"
+)
+
+// RegistrationServiceAssembly verifies the agent registration with Fulcrum Core before the agent starts processing
+// jobs. If the verification cannot be completed at startup, it is retried in the background.
+type RegistrationServiceAssembly struct {
+	system.DefaultServiceAssembly
+	registration   *Registration
+	onUnauthorized string
+	retryInterval  time.Duration
+	timeout        time.Duration
+	monitor        monitor.LogMonitor
+	cancel         context.CancelFunc
+	done           chan struct{}
+}
+
+func (a *RegistrationServiceAssembly) Name() string {
+	return "Registration"
+}
+
+func (a *RegistrationServiceAssembly) Provides() []system.ServiceType {
+	return []system.ServiceType{RegistrationKey}
+}
+
+func (a *RegistrationServiceAssembly) Requires() []system.ServiceType {
+	return []system.ServiceType{client.FulcrumClientKey}
+}
+
+func (a *RegistrationServiceAssembly) Init(context *system.InitContext) error {
+	a.onUnauthorized = context.GetConfigStrOrDefault(onUnauthorizedKey, OnUnauthorizedFail)
+	if a.onUnauthorized != OnUnauthorizedFail && a.onUnauthorized != OnUnauthorizedWait {
+		return fmt.Errorf("unsupported %s value %q, expected %s or %s",
+			onUnauthorizedKey, a.onUnauthorized, OnUnauthorizedFail, OnUnauthorizedWait)
+	}
+	a.retryInterval = defaultRetryInterval
+	if context.Config.IsSet(retryIntervalKey) {
+		a.retryInterval = context.Config.GetDuration(retryIntervalKey)
+	}
+	if a.retryInterval <= 0 {
+		return fmt.Errorf("%s must be positive", retryIntervalKey)
+	}
+	a.timeout = defaultTimeout
+	if context.Config.IsSet(timeoutKey) {
+		a.timeout = context.Config.GetDuration(timeoutKey)
+	}
+
+	fulcrumClient := context.Registry.Resolve(client.FulcrumClientKey).(client.FulcrumClient)
+	a.monitor = context.LogMonitor
+	a.registration = NewRegistration(fulcrumClient, context.LogMonitor)
+	context.Registry.Register(RegistrationKey, a.registration)
+	return nil
+}
+
+// Prepare verifies the registration once all assemblies are initialized and before any of them starts
+func (a *RegistrationServiceAssembly) Prepare() error {
+	err := a.verify(context.Background())
+	if errors.Is(err, ErrUnauthorized) && a.onUnauthorized == OnUnauthorizedFail {
+		return err
+	}
+	return nil
+}
+
+func (a *RegistrationServiceAssembly) Start(_ *system.StartContext) error {
+	if a.registration.Verified() {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
+	a.done = make(chan struct{})
+
+	go func() {
+		defer close(a.done)
+		ticker := time.NewTicker(a.retryInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if a.verify(ctx) == nil {
+					return
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (a *RegistrationServiceAssembly) Finalize() error {
+	if a.cancel == nil {
+		return nil
+	}
+	a.cancel()
+	<-a.done
+	return nil
+}
+
+// verify runs a single verification attempt and logs why it failed
+func (a *RegistrationServiceAssembly) verify(ctx context.Context) error {
+	if a.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.timeout)
+		defer cancel()
+	}
+	_, err := a.registration.Verify(ctx)
+	switch {
+	case err == nil:
+	case errors.Is(err, context.Canceled):
+		// shutting down
+	case errors.Is(err, ErrUnauthorized) && a.onUnauthorized == OnUnauthorizedFail:
+		a.monitor.Severef("Unable to verify agent registration: %v", err)
+	case errors.Is(err, ErrUnauthorized):
+		a.monitor.Warnf("Unable to verify agent registration, waiting for a valid token: %v", err)
+	default:
+		a.monitor.Warnf("Unable to verify agent registration, retrying in %v: %v", a.retryInterval, err)
+	}
+	return err
+}
@@ -0,0 +1,122 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package registration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/metaform/cfm-fulcrum/internal/client"
+	"github.com/metaform/connector-fabric-manager/common/monitor"
+	"net/http"
+	"sync"
+)
+
+// AgentIdentity identifies the agent registered in Fulcrum Core that owns the configured token
+type AgentIdentity struct {
+	ID            string
+	Name          string
+	ProviderID    string
+	ProviderName  string
+	AgentTypeID   string
+	AgentTypeName string
+}
+
+// ErrUnauthorized is returned by Verify when Fulcrum Core rejects the agent token
+var ErrUnauthorized = errors.New("agent token rejected by Fulcrum Core")
+
+// Registration verifies that the agent token belongs to an agent registered in Fulcrum Core and stores the identity
+// of that agent. Until verification succeeds the agent does not process jobs.
+type Registration struct {
+	fulcrumClient client.FulcrumClient
+	monitor       monitor.LogMonitor
+
+	mu       sync.RWMutex
+	identity *AgentIdentity
+}
+
+// NewRegistration creates an unverified registration
+func NewRegistration(fulcrumClient client.FulcrumClient, monitor monitor.LogMonitor) *Registration {
+	return &Registration{fulcrumClient: fulcrumClient, monitor: monitor}
+}
+
+// Verify retrieves the agent information from Fulcrum Core and stores the agent identity. ErrUnauthorized is returned
+// if the token is rejected.
+func (r *Registration) Verify(ctx context.Context) (AgentIdentity, error) {
+	info, err := r.fulcrumClient.GetAgentInfo(ctx)
+	if err != nil {
+		if client.HasStatus(err, http.StatusUnauthorized) || client.HasStatus(err, http.StatusForbidden) {
+			return AgentIdentity{}, fmt.Errorf("%w: %w", ErrUnauthorized, err)
+		}
+		return AgentIdentity{}, err
+	}
+	identity := newAgentIdentity(info)
+	if identity.ID == "" {
+		return AgentIdentity{}, errors.New("agent info returned by Fulcrum Core does not contain an agent ID")
+	}
+
+	r.mu.Lock()
+	r.identity = &identity
+	r.mu.Unlock()
+
+	r.monitor.Infof("Registered with Fulcrum Core as agent %s (%s), provider %s, agent type %s",
+		identity.ID, identity.Name, describe(identity.ProviderID, identity.ProviderName),
+		describe(identity.AgentTypeID, identity.AgentTypeName))
+	return identity, nil
+}
+
+// Identity returns the agent identity and true once the registration has been verified
+func (r *Registration) Identity() (AgentIdentity, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.identity == nil {
+		return AgentIdentity{}, false
+	}
+	return *r.identity, true
+}
+
+// Verified returns true once the registration has been verified
+func (r *Registration) Verified() bool {
+	_, verified := r.Identity()
+	return verified
+}
+
+// newAgentIdentity reads the identity from the agent information. Provider and agent type names are only available if
+// Fulcrum Core embeds the related entities.
+func newAgentIdentity(info map[string]any) AgentIdentity {
+	return AgentIdentity{
+		ID:            stringValue(info, "id"),
+		Name:          stringValue(info, "name"),
+		ProviderID:    stringValue(info, "providerId"),
+		ProviderName:  nestedName(info, "provider"),
+		AgentTypeID:   stringValue(info, "agentTypeId"),
+		AgentTypeName: nestedName(info, "agentType"),
+	}
+}
+
+func stringValue(values map[string]any, key string) string {
+	value, _ := values[key].(string)
+	return value
+}
+
+func nestedName(values map[string]any, key string) string {
+	nested, _ := values[key].(map[string]any)
+	return stringValue(nested, "name")
+}
+
+func describe(id string, name string) string {
+	if name == "" {
+		return id
+	}
+	return fmt.Sprintf("%s (%s)", id, name)
+}
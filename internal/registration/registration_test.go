@@ -0,0 +1,81 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package registration
+
+import (
+	"context"
+	"errors"
+	"github.com/metaform/cfm-fulcrum/internal/client"
+	"github.com/metaform/cfm-fulcrum/internal/client/mocks"
+	"github.com/metaform/connector-fabric-manager/common/monitor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func TestRegistration_Verify(t *testing.T) {
+	fulcrumClient := mocks.NewFulcrumClient(t)
+	fulcrumClient.EXPECT().GetAgentInfo(mock.Anything).Return(map[string]any{
+		"id":          "agent-1",
+		"name":        "Test Agent",
+		"providerId":  "provider-1",
+		"agentTypeId": "type-1",
+		"agentType":   map[string]any{"id": "type-1", "name": "CFM"},
+	}, nil).Once()
+	registration := NewRegistration(fulcrumClient, monitor.NoopMonitor{})
+
+	assert.False(t, registration.Verified())
+	identity, err := registration.Verify(context.Background())
+	require.NoError(t, err)
+
+	expected := AgentIdentity{
+		ID:            "agent-1",
+		Name:          "Test Agent",
+		ProviderID:    "provider-1",
+		AgentTypeID:   "type-1",
+		AgentTypeName: "CFM",
+	}
+	assert.Equal(t, expected, identity)
+	stored, verified := registration.Identity()
+	assert.True(t, verified)
+	assert.Equal(t, expected, stored)
+}
+
+func TestRegistration_VerifyErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		info         map[string]any
+		unauthorized bool
+	}{
+		{name: "unauthorized", err: &client.HTTPError{StatusCode: http.StatusUnauthorized}, unauthorized: true},
+		{name: "forbidden", err: &client.HTTPError{StatusCode: http.StatusForbidden}, unauthorized: true},
+		{name: "unavailable", err: &client.HTTPError{StatusCode: http.StatusServiceUnavailable}},
+		{name: "network error", err: errors.New("connection refused")},
+		{name: "missing ID", info: map[string]any{"name": "Test Agent"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fulcrumClient := mocks.NewFulcrumClient(t)
+			fulcrumClient.EXPECT().GetAgentInfo(mock.Anything).Return(tt.info, tt.err).Once()
+			registration := NewRegistration(fulcrumClient, monitor.NoopMonitor{})
+
+			_, err := registration.Verify(context.Background())
+			require.Error(t, err)
+			assert.Equal(t, tt.unauthorized, errors.Is(err, ErrUnauthorized))
+			assert.False(t, registration.Verified())
+		})
+	}
+}
@@ -0,0 +1,117 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package telemetry
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+	"github.com/metaform/cfm-fulcrum/internal/client"
+	"github.com/metaform/cfm-fulcrum/internal/job"
+	"github.com/metaform/connector-fabric-manager/assembly/routing"
+	"github.com/metaform/connector-fabric-manager/common/system"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"net/http"
+	"time"
+)
+
+const (
+	metricsPathKey         = "telemetry.metrics_path"
+	traceExporterKey       = "telemetry.tracing.exporter"
+	traceOTLPEndpointKey   = "telemetry.tracing.otlp.endpoint"
+	traceFileKey           = "telemetry.tracing.file"
+	traceSampleRatioKey    = "telemetry.tracing.sample_ratio"
+	traceServiceNameKey    = "telemetry.tracing.service_name"
+	tracingShutdownTimeout = 5 * time.Second
+
+	defaultMetricsPath      = "/metrics"
+	defaultTraceSampleRatio = 1.0
+	defaultTraceServiceName = "cfm-agent"
+)
+
+// TelemetryServiceAssembly records agent metrics and serves them for scraping by Prometheus. An empty metrics path
+// disables the route.
+//
+// If a trace exporter is configured, the assembly also installs the global tracer provider and the W3C trace context
+// propagator used for outgoing requests. Tracing is disabled by default.
+type TelemetryServiceAssembly struct {
+	system.DefaultServiceAssembly
+	metrics  *Metrics
+	tracing  *Tracing
+	registry *system.ServiceRegistry
+}
+
+func (a *TelemetryServiceAssembly) Name() string {
+	return "Telemetry"
+}
+
+func (a *TelemetryServiceAssembly) Provides() []system.ServiceType {
+	return []system.ServiceType{client.RequestObserverKey, job.ObserverKey}
+}
+
+func (a *TelemetryServiceAssembly) Requires() []system.ServiceType {
+	return []system.ServiceType{routing.RouterKey}
+}
+
+func (a *TelemetryServiceAssembly) Init(context *system.InitContext) error {
+	a.metrics = NewMetrics()
+	a.registry = context.Registry
+	context.Registry.Register(client.RequestObserverKey, a.metrics)
+	context.Registry.Register(job.ObserverKey, a.metrics)
+
+	if path := context.GetConfigStrOrDefault(metricsPathKey, defaultMetricsPath); path != "" {
+		router := context.Registry.Resolve(routing.RouterKey).(chi.Router)
+		router.Method(http.MethodGet, path, a.metrics.Handler())
+	}
+
+	sampleRatio := defaultTraceSampleRatio
+	if context.Config.IsSet(traceSampleRatioKey) {
+		sampleRatio = context.Config.GetFloat64(traceSampleRatioKey)
+	}
+	tracing, err := NewTracing(TracingConfig{
+		Exporter:     context.GetConfigStrOrDefault(traceExporterKey, ExporterNone),
+		OTLPEndpoint: context.GetConfigStrOrDefault(traceOTLPEndpointKey, ""),
+		File:         context.GetConfigStrOrDefault(traceFileKey, ""),
+		SampleRatio:  sampleRatio,
+		ServiceName:  context.GetConfigStrOrDefault(traceServiceNameKey, defaultTraceServiceName),
+	})
+	if err != nil {
+		return err
+	}
+	if tracing != nil {
+		a.tracing = tracing
+		otel.SetTracerProvider(tracing.Provider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+		context.LogMonitor.Infof("Exporting traces to %s", context.GetConfigStrOrDefault(traceExporterKey, ExporterNone))
+	}
+	return nil
+}
+
+// Prepare tracks the token age once the client assembly, which requires this assembly, has been initialized
+func (a *TelemetryServiceAssembly) Prepare() error {
+	if tracker, found := a.registry.ResolveOptional(client.TokenTrackerKey); found {
+		a.metrics.TrackTokenAge(tracker.(*client.TokenTracker))
+	}
+	return nil
+}
+
+// Shutdown exports the remaining spans. It runs after all assemblies have been finalized, so the spans of jobs
+// completed during shutdown are included.
+func (a *TelemetryServiceAssembly) Shutdown() error {
+	if a.tracing == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+	defer cancel()
+	return a.tracing.Shutdown(ctx)
+}
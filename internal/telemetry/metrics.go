@@ -0,0 +1,125 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package telemetry
+
+import (
+	"github.com/metaform/cfm-fulcrum/internal/client"
+	"github.com/metaform/cfm-fulcrum/internal/job"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const namespace = "cfm_agent"
+
+// Metrics records agent metrics in a Prometheus registry. It is a client.RequestObserver and a job.Observer.
+type Metrics struct {
+	registry        *prometheus.Registry
+	jobs            *prometheus.CounterVec
+	jobDuration     *prometheus.HistogramVec
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	pollLag         prometheus.Gauge
+	lastPollTime    prometheus.Gauge
+}
+
+// NewMetrics creates the agent metrics, including the Go runtime and process metrics
+func NewMetrics() *Metrics {
+	registry := prometheus.NewRegistry()
+	m := &Metrics{
+		registry: registry,
+		jobs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "jobs_total",
+			Help:      "Jobs handled by the agent by action and outcome.",
+		}, []string{"action", "outcome"}),
+		jobDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "job_duration_seconds",
+			Help:      "Time from claiming a job to reporting its result to Fulcrum Core.",
+			Buckets:   []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200, 1800, 3600},
+		}, []string{"action", "outcome"}),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "upstream_requests_total",
+			Help:      "Request attempts sent to upstream services. The status is 0 if no response was received.",
+		}, []string{"upstream", "method", "endpoint", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "upstream_request_duration_seconds",
+			Help:      "Latency of request attempts sent to upstream services.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"upstream", "method", "endpoint", "status"}),
+		pollLag: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "poll_lag_seconds",
+			Help:      "Delay of the last job poll cycle past its scheduled time.",
+		}),
+		lastPollTime: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "last_poll_timestamp_seconds",
+			Help:      "Time the last job poll cycle started.",
+		}),
+	}
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.jobs,
+		m.jobDuration,
+		m.requests,
+		m.requestDuration,
+		m.pollLag,
+		m.lastPollTime,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ObserveRequest implements client.RequestObserver
+func (m *Metrics) ObserveRequest(upstream string, method string, endpoint string, status int, duration time.Duration) {
+	labels := prometheus.Labels{"upstream": upstream, "method": method, "endpoint": endpoint, "status": strconv.Itoa(status)}
+	m.requests.With(labels).Inc()
+	m.requestDuration.With(labels).Observe(duration.Seconds())
+}
+
+// ObserveJob implements job.Observer
+func (m *Metrics) ObserveJob(action client.JobAction, outcome job.Outcome, duration time.Duration) {
+	m.jobs.WithLabelValues(string(action), string(outcome)).Inc()
+	if duration > 0 {
+		m.jobDuration.WithLabelValues(string(action), string(outcome)).Observe(duration.Seconds())
+	}
+}
+
+// ObservePoll implements job.Observer
+func (m *Metrics) ObservePoll(lag time.Duration) {
+	m.pollLag.Set(max(lag, 0).Seconds())
+	m.lastPollTime.SetToCurrentTime()
+}
+
+// TrackTokenAge exposes the age of the token recorded by the tracker
+func (m *Metrics) TrackTokenAge(tracker *client.TokenTracker) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "token_age_seconds",
+		Help:      "Age of the token used to authenticate with Fulcrum Core.",
+	}, func() float64 {
+		return tracker.Info().Age(time.Now()).Seconds()
+	}))
+}
@@ -0,0 +1,54 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package telemetry
+
+import (
+	"github.com/metaform/cfm-fulcrum/internal/client"
+	"github.com/metaform/cfm-fulcrum/internal/job"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMetrics_Handler(t *testing.T) {
+	metrics := NewMetrics()
+	metrics.TrackTokenAge(client.NewTokenTracker("token", "", time.Now().Add(-time.Hour)))
+
+	metrics.ObserveJob(client.JobActionServiceCreate, job.OutcomeSucceeded, 2*time.Second)
+	metrics.ObserveJob(client.JobActionServiceCreate, job.OutcomeConflict, 0)
+	metrics.ObservePoll(250 * time.Millisecond)
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer upstream.Close()
+	transport := client.NewObservingTransport(http.DefaultTransport, client.UpstreamFulcrum, metrics)
+	resp, err := (&http.Client{Transport: transport}).Post(upstream.URL+"/api/v1/jobs/0f8fad5b-d9cb-469f-a165-70867728950e/claim", "application/json", nil)
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+
+	assert.Contains(t, body, `cfm_agent_jobs_total{action="ServiceCreate",outcome="succeeded"} 1`)
+	assert.Contains(t, body, `cfm_agent_jobs_total{action="ServiceCreate",outcome="conflict"} 1`)
+	assert.Contains(t, body, `cfm_agent_job_duration_seconds_count{action="ServiceCreate",outcome="succeeded"} 1`)
+	assert.NotContains(t, body, `cfm_agent_job_duration_seconds_count{action="ServiceCreate",outcome="conflict"}`)
+	assert.Contains(t, body, `cfm_agent_upstream_requests_total{endpoint="/api/v1/jobs/{id}/claim",method="POST",status="202",upstream="fulcrum"} 1`)
+	assert.Contains(t, body, "cfm_agent_poll_lag_seconds 0.25")
+	assert.Contains(t, body, "cfm_agent_token_age_seconds 3600")
+	assert.Contains(t, body, "go_goroutines")
+}
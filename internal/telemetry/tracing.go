@@ -0,0 +1,106 @@
+//  Copyright (c) 2025 Metaform Systems, Inc
+//
+//  This program and the accompanying materials are made available under the
+//  terms of the Apache License, Version 2.0 which is available at
+//  https://www.apache.org/licenses/LICENSE-2.0
+//
+//  SPDX-License-Identifier: Apache-2.0
+//
+//  Contributors:
+//       Metaform Systems, Inc. - initial API and implementation
+//
+
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"os"
+)
+
+// Supported values of the telemetry.tracing.exporter setting
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+)
+
+// TracingConfig configures how spans are exported
+type TracingConfig struct {
+	Exporter     string  // One of none, otlp, stdout or file
+	OTLPEndpoint string  // OTLP/HTTP endpoint URL; the OTEL_EXPORTER_OTLP_* environment variables apply if empty
+	File         string  // Path spans are appended to by the file exporter
+	SampleRatio  float64 // Fraction of root spans sampled; child spans follow their parent
+	ServiceName  string  // Reported as the service.name resource attribute
+}
+
+// Tracing owns the tracer provider and the resources of its exporter
+type Tracing struct {
+	provider *sdktrace.TracerProvider
+	file     *os.File
+}
+
+// NewTracing creates a tracer provider exporting spans as configured. It returns nil if tracing is disabled.
+func NewTracing(config TracingConfig) (*Tracing, error) {
+	tracing := &Tracing{}
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch config.Exporter {
+	case ExporterNone, "":
+		return nil, nil
+	case ExporterOTLP:
+		var options []otlptracehttp.Option
+		if config.OTLPEndpoint != "" {
+			options = append(options, otlptracehttp.WithEndpointURL(config.OTLPEndpoint))
+		}
+		exporter, err = otlptracehttp.New(context.Background(), options...)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New()
+	case ExporterFile:
+		if config.File == "" {
+			return nil, fmt.Errorf("a file is required for the %s trace exporter", ExporterFile)
+		}
+		tracing.file, err = os.OpenFile(config.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open trace file: %w", err)
+		}
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(tracing.file))
+	default:
+		return nil, fmt.Errorf("unsupported trace exporter %q", config.Exporter)
+	}
+	if err != nil {
+		tracing.closeFile()
+		return nil, fmt.Errorf("failed to create %s trace exporter: %w", config.Exporter, err)
+	}
+
+	tracing.provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", config.ServiceName))),
+	)
+	return tracing, nil
+}
+
+// Provider returns the tracer provider
+func (t *Tracing) Provider() *sdktrace.TracerProvider {
+	return t.provider
+}
+
+// Shutdown exports pending spans and releases the exporter
+func (t *Tracing) Shutdown(ctx context.Context) error {
+	err := t.provider.Shutdown(ctx)
+	t.closeFile()
+	return err
+}
+
+func (t *Tracing) closeFile() {
+	if t.file != nil {
+		t.file.Close()
+	}
+}